@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type BlockerRequestBody struct {
+	BlockerID int `json:"blocker_id"`
+}
+
+func AddBlocker(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		reqBody, err := parseBlockerRequestBody(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.AddBlocker(id, reqBody.BlockerID)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func RemoveBlocker(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		blockerID, err := strconv.Atoi(ps.ByName("blocker"))
+		if err != nil {
+			writeError(writer, utils.NewError(utils.ErrInvalidID, "error converting blocker to number: %v", err))
+			return
+		}
+
+		item, err := store.RemoveBlocker(id, blockerID)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func Ready(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		items := store.Ready()
+
+		b, err := json.Marshal(items)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func parseBlockerRequestBody(request *http.Request) (*BlockerRequestBody, error) {
+	var r BlockerRequestBody
+	err := decodeJSON(request, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.BlockerID == 0 {
+		return nil, utils.NewFieldError(utils.ErrValidation, "blocker_id", "\"blocker_id\" field in body was not populated")
+	}
+
+	return &r, nil
+}
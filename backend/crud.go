@@ -5,29 +5,59 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
-	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type RequestBody struct {
+	// Item is the item's title; on update leaving it out keeps the title.
 	Item string `json:"item"`
+	// Notes is an optional multi-line markdown description; on update
+	// leaving it out keeps the notes and "" clears them.
+	Notes *string `json:"notes,omitempty"`
+	// Due is an optional RFC 3339 date; on update an empty value leaves the
+	// item's due date unchanged.
+	Due string `json:"due,omitempty"`
+	// Priority is a name or a number from 1 to 4; on update leaving it out
+	// keeps the item's priority and "none" clears it.
+	Priority *utils.Priority `json:"priority,omitempty"`
+	// ParentID makes the item a subtask of another item; on update leaving it
+	// out keeps the item where it is and 0 makes it a top-level item.
+	ParentID *int `json:"parent_id,omitempty"`
+	// Recurrence is a rule like "weekly" or a cron expression; on update
+	// leaving it out keeps the item's rule and "" stops the item recurring.
+	Recurrence *string `json:"recurrence,omitempty"`
+}
+
+// ReadItemResponse adds the due date of a recurring item's next occurrence
+// to the item.
+type ReadItemResponse struct {
+	utils.ItemTree
+	NextOccurrence *time.Time `json:"next_occurrence,omitempty"`
 }
 
 type CountResponse struct {
 	Count int `json:"count"`
+	// ByPriority is only filled in for /count?by=priority.
+	ByPriority map[string]int `json:"by_priority,omitempty"`
 }
 
-func PrintItems(itemList *utils.ItemList) httprouter.Handle {
+func PrintItems(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
-		items := itemList.ReadAll()
+		order, err := utils.ParseSortOrder(request.URL.Query().Get("sort"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		items := store.Find(utils.Query{Sort: order})
 
 		result := "TO-DO LIST\n" +
 			"----------\n"
 
-		for _, item := range items {
-			result += fmt.Sprintf("%v. %v\n", item.ID, item.Item)
-		}
+		result += printOutline(utils.BuildForest(items), "", time.Now())
 		if len(items) == 0 {
 			result += "Looking kind of empty...\n"
 		}
@@ -36,14 +66,58 @@ func PrintItems(itemList *utils.ItemList) httprouter.Handle {
 	})
 }
 
-func CreateItem(itemList *utils.ItemList) httprouter.Handle {
+// printOutline prints the items with subtasks indented below their parent.
+func printOutline(trees []utils.ItemTree, indent string, now time.Time) string {
+	result := ""
+
+	for _, tree := range trees {
+		item := tree.ItemAndID
+
+		checkbox := "[ ]"
+		if item.Done {
+			checkbox = "[x]"
+		}
+
+		result += fmt.Sprintf("%v%v. %v %v", indent, item.ID, checkbox, item.Item)
+		if tree.Progress != nil {
+			result += fmt.Sprintf(" (%v/%v done)", tree.Progress.Done, tree.Progress.Total)
+		}
+		if item.Priority != utils.PriorityNone {
+			result += fmt.Sprintf(" (%v priority)", item.Priority)
+		}
+		if item.Due != nil {
+			result += fmt.Sprintf(" (due %v)", item.Due.Format(time.RFC3339))
+		}
+		if item.IsOverdue(now) {
+			result += " OVERDUE"
+		}
+		result += "\n"
+
+		result += printOutline(tree.Children, indent+"  ", now)
+	}
+
+	return result
+}
+
+func CreateItem(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
 		reqBody, err := parseRequestBody(request)
 		if err != nil {
 			writeError(writer, err)
 			return
 		} else {
-			item := itemList.CreateItem(reqBody.Item)
+			opts, err := reqBody.itemOptions()
+			if err != nil {
+				writeError(writer, err)
+				return
+			}
+			opts = append(opts, getActor(request)...)
+
+			item, err := store.CreateItem(reqBody.Item, opts...)
+			if err != nil {
+				writeError(writer, err)
+				return
+			}
 
 			b, err := json.Marshal(item)
 			if err != nil {
@@ -51,12 +125,13 @@ func CreateItem(itemList *utils.ItemList) httprouter.Handle {
 				return
 			}
 
+			setETag(writer, item)
 			writer.Write(b)
 		}
 	})
 }
 
-func ReadItem(itemList *utils.ItemList) httprouter.Handle {
+func ReadItem(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
 		id, err := getID(ps)
 		if err != nil {
@@ -64,25 +139,126 @@ func ReadItem(itemList *utils.ItemList) httprouter.Handle {
 			return
 		}
 
-		item, err := itemList.ReadItem(id)
+		withChildren, err := getBoolParam(request, "children")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.ReadTree(id)
 		if err != nil {
 			writeError(writer, err)
 			return
 		} else {
-			b, err := json.Marshal(item)
+			if notModified(request, item.ItemAndID) {
+				setETag(writer, item.ItemAndID)
+				writer.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			if !withChildren {
+				item.Children = nil
+			}
+
+			response := ReadItemResponse{
+				ItemTree:       item,
+				NextOccurrence: item.NextOccurrence(time.Now()),
+			}
+
+			b, err := json.Marshal(response)
 			if err != nil {
 				writeError(writer, err)
 				return
 			}
 
+			setETag(writer, item.ItemAndID)
 			writer.Write(b)
 		}
 	})
 }
 
-func ReadAll(itemList *utils.ItemList) httprouter.Handle {
+func ReadAll(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		status, err := utils.ParseStatus(request.URL.Query().Get("status"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		order, err := utils.ParseSortOrder(request.URL.Query().Get("sort"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		tags, tagMode, err := getTagParams(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		withNotes, err := getFieldsParam(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		asOf, err := getTimeParam(request, "as_of")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		filter, err := utils.ParseFilter(request.URL.Query().Get("q"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		page, paginated, err := getPageParams(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		query := utils.Query{Status: status, Tags: tags, TagMode: tagMode, AsOf: asOf, Filter: filter, Sort: order}
+		items := store.Find(query)
+
+		// notes can be long, so lists leave them out unless asked for
+		if !withNotes {
+			for i := range items {
+				items[i].Notes = ""
+			}
+		}
+
+		// without page parameters the whole list is a plain array, as it
+		// always was
+		var response interface{} = items
+		if paginated {
+			result, err := query.Paginate(items, page)
+			if err != nil {
+				writeError(writer, err)
+				return
+			}
+
+			setLinkHeader(writer, request, page, result)
+			response = result
+		}
+
+		b, err := json.Marshal(response)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func Overdue(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
-		items := itemList.ReadAll()
+		now := time.Now()
+		items := store.Find(utils.Query{Status: utils.StatusOpen, DueBefore: &now})
 
 		b, err := json.Marshal(items)
 		if err != nil {
@@ -94,7 +270,33 @@ func ReadAll(itemList *utils.ItemList) httprouter.Handle {
 	})
 }
 
-func UpdateItem(itemList *utils.ItemList) httprouter.Handle {
+func DueItems(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		before, err := getTimeParam(request, "before")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		after, err := getTimeParam(request, "after")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		items := store.Find(utils.Query{HasDue: true, DueBefore: before, DueAfter: after})
+
+		b, err := json.Marshal(items)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func UpdateItem(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
 		id, err := getID(ps)
 		if err != nil {
@@ -102,18 +304,27 @@ func UpdateItem(itemList *utils.ItemList) httprouter.Handle {
 			return
 		}
 
-		reqBody, err := parseRequestBody(request)
+		preconditions, err := getIfMatch(request)
 		if err != nil {
 			writeError(writer, err)
 			return
 		}
 
-		if reqBody.Item == "" {
+		reqBody, err := parseUpdateRequestBody(request)
+		if err != nil {
 			writeError(writer, err)
 			return
 		}
 
-		item, err := itemList.UpdateItem(id, reqBody.Item)
+		opts, err := reqBody.itemOptions()
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		opts = append(opts, preconditions...)
+		opts = append(opts, getActor(request)...)
+
+		item, err := store.UpdateItem(id, reqBody.Item, opts...)
 		if err != nil {
 			writeError(writer, err)
 			return
@@ -125,19 +336,20 @@ func UpdateItem(itemList *utils.ItemList) httprouter.Handle {
 			return
 		}
 
+		setETag(writer, item)
 		writer.Write(b)
 	})
 }
 
-func DeleteItem(itemList *utils.ItemList) httprouter.Handle {
+func CompleteItem(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
-		index, err := getID(ps)
+		id, err := getID(ps)
 		if err != nil {
 			writeError(writer, err)
 			return
 		}
 
-		item, err := itemList.DeleteItem(index)
+		item, err := store.CompleteItem(id)
 		if err != nil {
 			writeError(writer, err)
 			return
@@ -149,13 +361,87 @@ func DeleteItem(itemList *utils.ItemList) httprouter.Handle {
 			return
 		}
 
+		setETag(writer, item)
 		writer.Write(b)
 	})
 }
 
-func DeleteAll(itemList *utils.ItemList) httprouter.Handle {
+func ReopenItem(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
-		items := itemList.DeleteAll()
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.ReopenItem(id)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func DeleteItem(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		index, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		cascade, err := getBoolParam(request, "cascade")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		preconditions, err := getIfMatch(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		opts := append(preconditions, getActor(request)...)
+
+		// deleting an item with subtasks fails unless they are deleted along
+		// with it, in which case all of the deleted items are returned
+		var deleted interface{}
+		if cascade {
+			deleted, err = store.DeleteTree(index, opts...)
+		} else {
+			deleted, err = store.DeleteItem(index, opts...)
+		}
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(deleted)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func DeleteAll(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		items, err := store.DeleteAll()
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
 
 		b, err := json.Marshal(items)
 		if err != nil {
@@ -167,10 +453,27 @@ func DeleteAll(itemList *utils.ItemList) httprouter.Handle {
 	})
 }
 
-func Count(itemList *utils.ItemList) httprouter.Handle {
+func Count(store utils.Store) httprouter.Handle {
 	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
-		count := len(itemList.ReadAll())
-		response := CountResponse{Count: count}
+		response := CountResponse{Count: store.Count()}
+
+		switch by := request.URL.Query().Get("by"); by {
+		case "":
+		case "priority":
+			response.ByPriority = map[string]int{}
+			for _, priority := range utils.Priorities {
+				response.ByPriority[priority.String()] = 0
+			}
+
+			items := store.ReadAll()
+			for _, item := range items {
+				response.ByPriority[item.Priority.String()]++
+			}
+			response.Count = len(items)
+		default:
+			writeError(writer, utils.NewFieldError(utils.ErrValidation, "by", "by (%v) has to be priority", by))
+			return
+		}
 
 		b, err := json.Marshal(response)
 		if err != nil {
@@ -185,32 +488,130 @@ func Count(itemList *utils.ItemList) httprouter.Handle {
 func getID(ps httprouter.Params) (int, error) {
 	id, err := strconv.Atoi(ps.ByName("id"))
 	if err != nil {
-		return 0, fmt.Errorf("error converting id to number: %v", err)
+		return 0, utils.NewError(utils.ErrInvalidID, "error converting id to number: %v", err)
 	}
 
 	return id, nil
 }
 
+// getBoolParam parses the query parameter, which defaults to false.
+func getBoolParam(request *http.Request, name string) (bool, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, utils.NewFieldError(utils.ErrValidation, name, "%q parameter (%v) has to be true or false", name, value)
+	}
+
+	return b, nil
+}
+
+// getFieldsParam reports whether the optional fields left out of lists by
+// default were asked for with "fields"; notes are the only such field.
+func getFieldsParam(request *http.Request) (bool, error) {
+	withNotes := false
+	for _, value := range request.URL.Query()["fields"] {
+		for _, field := range strings.Split(value, ",") {
+			if field != "notes" {
+				return false, utils.NewFieldError(utils.ErrValidation, "fields", "fields (%v) has to be notes", field)
+			}
+
+			withNotes = true
+		}
+	}
+
+	return withNotes, nil
+}
+
+// getTimeParam parses the RFC 3339 query parameter, returning nil if it is
+// not set.
+func getTimeParam(request *http.Request, name string) (*time.Time, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, utils.NewFieldError(utils.ErrValidation, name, "%q parameter (%v) is not an RFC 3339 date", name, value)
+	}
+
+	return &t, nil
+}
+
 func parseRequestBody(request *http.Request) (*RequestBody, error) {
-	b, err := io.ReadAll(request.Body)
+	r, err := decodeRequestBody(request)
 	if err != nil {
 		return nil, err
 	}
 
-	var r RequestBody
-	err = json.Unmarshal(b, &r)
+	if r.Item == "" {
+		return nil, utils.NewFieldError(utils.ErrValidation, "item", "\"item\" field in body was not populated")
+	}
+
+	return r, nil
+}
+
+// parseUpdateRequestBody is like parseRequestBody but the item may be left
+// out as long as something else is changed.
+func parseUpdateRequestBody(request *http.Request) (*RequestBody, error) {
+	r, err := decodeRequestBody(request)
 	if err != nil {
 		return nil, err
 	}
 
-	if r.Item == "" {
-		return nil, fmt.Errorf("\"item\" field in body was not populated")
+	if r.Item == "" && *r == (RequestBody{}) {
+		return nil, utils.NewFieldError(utils.ErrValidation, "item", "\"item\" field in body was not populated")
+	}
+
+	return r, nil
+}
+
+func decodeRequestBody(request *http.Request) (*RequestBody, error) {
+	var r RequestBody
+	err := decodeJSON(request, &r)
+	if err != nil {
+		return nil, err
 	}
 
 	return &r, nil
 }
 
-func writeError(writer http.ResponseWriter, err error) {
-	writer.WriteHeader(http.StatusBadRequest)
-	writer.Write([]byte(err.Error()))
+func (r *RequestBody) itemOptions() ([]utils.ItemOption, error) {
+	var opts []utils.ItemOption
+
+	if r.Notes != nil {
+		opts = append(opts, utils.WithNotes(*r.Notes))
+	}
+
+	if r.Due != "" {
+		due, err := time.Parse(time.RFC3339, r.Due)
+		if err != nil {
+			return nil, utils.NewFieldError(utils.ErrValidation, "due", "\"due\" field (%v) is not an RFC 3339 date", r.Due)
+		}
+
+		opts = append(opts, utils.WithDue(due))
+	}
+
+	if r.Priority != nil {
+		opts = append(opts, utils.WithPriority(*r.Priority))
+	}
+
+	if r.ParentID != nil {
+		opts = append(opts, utils.WithParent(*r.ParentID))
+	}
+
+	if r.Recurrence != nil {
+		recurrence, err := utils.ParseRecurrence(*r.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, utils.WithRecurrence(recurrence))
+	}
+
+	return opts, nil
 }
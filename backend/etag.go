@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// An item's ETag is its version, so that clients can send it back with
+// If-Match to make sure nobody changed the item in the meantime.
+func etag(item utils.ItemAndID) string {
+	return strconv.Quote(strconv.Itoa(item.Version))
+}
+
+func setETag(writer http.ResponseWriter, item utils.ItemAndID) {
+	writer.Header().Set("ETag", etag(item))
+}
+
+// getIfMatch turns the If-Match header, a comma separated list of ETags,
+// into an IfVersion option that matches any of them; a missing header or
+// "*" don't restrict the version. If-Match compares ETags strongly, so weak
+// ones never match.
+func getIfMatch(request *http.Request) ([]utils.ItemOption, error) {
+	value := strings.TrimSpace(request.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	versions := []int{}
+	rest := value
+	for {
+		// empty list elements are allowed
+		rest = strings.TrimLeft(rest, ", \t")
+		if rest == "" {
+			break
+		}
+
+		weak := strings.HasPrefix(rest, "W/")
+		rest = strings.TrimPrefix(rest, "W/")
+		end := -1
+		if strings.HasPrefix(rest, `"`) {
+			end = strings.Index(rest[1:], `"`)
+		}
+		if end < 0 {
+			return nil, utils.NewError(ErrMalformedRequest, "If-Match header (%v) has to be a list of ETags or *", value)
+		}
+
+		opaque := rest[1 : end+1]
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		if rest != "" && !strings.HasPrefix(rest, ",") {
+			return nil, utils.NewError(ErrMalformedRequest, "If-Match header (%v) has to be a list of ETags or *", value)
+		}
+
+		// no item has an ETag that isn't a version, so it can't match either
+		version, err := strconv.Atoi(opaque)
+		if err == nil && !weak {
+			versions = append(versions, version)
+		}
+	}
+
+	return []utils.ItemOption{utils.IfVersion(versions...)}, nil
+}
+
+// notModified reports whether the If-None-Match header lists the item's
+// ETag or is "*". Weak ETags compare like strong ones.
+func notModified(request *http.Request, item utils.ItemAndID) bool {
+	value := request.Header.Get("If-None-Match")
+	if value == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(item) {
+			return true
+		}
+	}
+
+	return false
+}
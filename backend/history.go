@@ -0,0 +1,44 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
+)
+
+func ReadHistory(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		revisions, err := store.History(id)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(revisions)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+// getActor attributes the change to whoever the X-Actor header names, if
+// anyone.
+func getActor(request *http.Request) []utils.ItemOption {
+	actor := strings.TrimSpace(request.Header.Get("X-Actor"))
+	if actor == "" {
+		return nil
+	}
+
+	return []utils.ItemOption{utils.ByActor(actor)}
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type ListRequestBody struct {
+	Name string `json:"name"`
+}
+
+func ReadLists(lists *utils.Lists) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		b, err := json.Marshal(lists.All())
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func CreateList(lists *utils.Lists) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		reqBody, err := parseListRequestBody(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		list, err := lists.Create(reqBody.Name)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(list)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+func DeleteList(lists *utils.Lists) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		list, err := lists.Delete(ps.ByName("list"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(list)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+// inList serves the request with the handler for the store of the list named
+// in the path.
+func inList(lists *utils.Lists, handler func(store utils.Store) httprouter.Handle) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		store, err := lists.Get(ps.ByName("list"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		handler(store)(writer, request, ps)
+	})
+}
+
+func parseListRequestBody(request *http.Request) (*ListRequestBody, error) {
+	var r ListRequestBody
+	err := decodeJSON(request, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Name == "" {
+		return nil, utils.NewFieldError(utils.ErrValidation, "name", "\"name\" field in body was not populated")
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// MoveRequestBody says where an item is moved to; exactly one of the fields
+// has to be given.
+type MoveRequestBody struct {
+	Before *int `json:"before,omitempty"`
+	After  *int `json:"after,omitempty"`
+	// Position is the item's new place in the list, 1 being the top.
+	Position *int `json:"position,omitempty"`
+}
+
+func MoveItem(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		reqBody, err := parseMoveRequestBody(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.MoveItem(id, reqBody.placement())
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func (r *MoveRequestBody) placement() utils.Placement {
+	switch {
+	case r.Before != nil:
+		return utils.MoveBefore(*r.Before)
+	case r.After != nil:
+		return utils.MoveAfter(*r.After)
+	default:
+		return utils.MoveToPosition(*r.Position)
+	}
+}
+
+func parseMoveRequestBody(request *http.Request) (*MoveRequestBody, error) {
+	var r MoveRequestBody
+	err := decodeJSON(request, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	given := 0
+	for _, field := range []*int{r.Before, r.After, r.Position} {
+		if field != nil {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, utils.NewError(utils.ErrValidation, "exactly one of the \"before\", \"after\" and \"position\" fields has to be given in body")
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// getPageParams parses the "limit", "offset" and "cursor" query parameters
+// and reports whether any of them was given.
+func getPageParams(request *http.Request) (utils.Page, bool, error) {
+	query := request.URL.Query()
+
+	var page utils.Page
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"limit", &page.Limit}, {"offset", &page.Offset}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return utils.Page{}, false, utils.NewFieldError(utils.ErrValidation, param.name, "%q parameter (%v) has to be a number", param.name, value)
+		}
+		*param.value = n
+	}
+	page.Cursor = query.Get("cursor")
+
+	paginated := query.Has("limit") || query.Has("offset") || query.Has("cursor")
+	return page, paginated, nil
+}
+
+// setLinkHeader links the first and the next page and, when paging by
+// offset, the previous one.
+func setLinkHeader(writer http.ResponseWriter, request *http.Request, page utils.Page, result utils.ItemPage) {
+	links := []string{pageLink(request, "first", nil)}
+
+	if page.Cursor != "" || page.Offset == 0 {
+		if result.NextCursor != "" {
+			links = append(links, pageLink(request, "next", map[string]string{"cursor": result.NextCursor}))
+		}
+	} else {
+		if result.NextCursor != "" {
+			next := page.Offset + len(result.Items)
+			links = append(links, pageLink(request, "next", map[string]string{"offset": strconv.Itoa(next)}))
+		}
+
+		prev := page.Offset - page.Size()
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(request, "prev", map[string]string{"offset": strconv.Itoa(prev)}))
+	}
+
+	writer.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageLink returns a link to the request's URL with the page parameters
+// replaced by the given ones.
+func pageLink(request *http.Request, rel string, params map[string]string) string {
+	query := request.URL.Query()
+	query.Del("cursor")
+	query.Del("offset")
+	for name, value := range params {
+		query.Set(name, value)
+	}
+
+	u := *request.URL
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf("<%v>; rel=%q", u.RequestURI(), rel)
+}
@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// patchFields are the fields a merge patch can change, in the order they are
+// applied. A null value clears the field.
+var patchFields = []struct {
+	name  string
+	parse func(value json.RawMessage) (utils.ItemOption, error)
+	clear utils.ItemOption
+}{
+	{
+		name: "notes",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var notes string
+			err := decodePatchValue("notes", value, &notes, "a string")
+			return utils.WithNotes(notes), err
+		},
+		clear: utils.WithNotes(""),
+	},
+	{
+		name: "due",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var s string
+			err := decodePatchValue("due", value, &s, "an RFC 3339 date")
+			if err != nil {
+				return nil, err
+			}
+
+			due, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, utils.NewFieldError(utils.ErrValidation, "due", "\"due\" field (%v) is not an RFC 3339 date", s)
+			}
+
+			return utils.WithDue(due), nil
+		},
+		clear: utils.WithoutDue(),
+	},
+	{
+		name: "priority",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var priority utils.Priority
+			err := json.Unmarshal(value, &priority)
+			return utils.WithPriority(priority), err
+		},
+		clear: utils.WithPriority(utils.PriorityNone),
+	},
+	{
+		name: "tags",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var tags []string
+			err := decodePatchValue("tags", value, &tags, "a list of strings")
+			if err != nil {
+				return nil, err
+			}
+
+			for i, tag := range tags {
+				tags[i], err = utils.NormalizeTag(tag)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return utils.WithTags(tags...), nil
+		},
+		clear: utils.WithTags(),
+	},
+	{
+		name: "parent_id",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var parentID int
+			err := decodePatchValue("parent_id", value, &parentID, "a number")
+			return utils.WithParent(parentID), err
+		},
+		clear: utils.WithParent(0),
+	},
+	{
+		name: "recurrence",
+		parse: func(value json.RawMessage) (utils.ItemOption, error) {
+			var rule string
+			err := decodePatchValue("recurrence", value, &rule, "a string")
+			if err != nil {
+				return nil, err
+			}
+
+			recurrence, err := utils.ParseRecurrence(rule)
+			return utils.WithRecurrence(recurrence), err
+		},
+		clear: utils.WithRecurrence(utils.Recurrence{}),
+	},
+}
+
+// PatchItem applies an RFC 7396 merge patch to the item: the fields present
+// in the patch are changed, null clears them and all other fields are kept.
+func PatchItem(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if err != nil || mediaType != mergePatchContentType {
+			writeError(writer, utils.NewError(ErrUnsupportedMediaType, "content type (%v) has to be %v", request.Header.Get("Content-Type"), mergePatchContentType))
+			return
+		}
+
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		preconditions, err := getIfMatch(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		newItem, opts, err := parseMergePatch(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		opts = append(opts, preconditions...)
+		opts = append(opts, getActor(request)...)
+
+		item, err := store.UpdateItem(id, newItem, opts...)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+// parseMergePatch returns the item's new text, or "" to keep it, and the
+// options for the other fields of the patch.
+func parseMergePatch(request *http.Request) (string, []utils.ItemOption, error) {
+	b, err := io.ReadAll(request.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// a patch that isn't an object would replace the whole item
+	var patch map[string]json.RawMessage
+	err = json.Unmarshal(b, &patch)
+	if err != nil || patch == nil {
+		return "", nil, utils.NewError(ErrMalformedRequest, "patch has to be a JSON object")
+	}
+
+	newItem := ""
+	if value, ok := patch["item"]; ok {
+		err = decodePatchValue("item", value, &newItem, "a string")
+		if err != nil {
+			return "", nil, err
+		}
+		if newItem == "" {
+			return "", nil, utils.NewFieldError(utils.ErrValidation, "item", "\"item\" field can't be empty")
+		}
+		delete(patch, "item")
+	}
+
+	var opts []utils.ItemOption
+	for _, field := range patchFields {
+		value, ok := patch[field.name]
+		if !ok {
+			continue
+		}
+		delete(patch, field.name)
+
+		if isNull(value) {
+			opts = append(opts, field.clear)
+			continue
+		}
+
+		opt, err := field.parse(value)
+		if err != nil {
+			return "", nil, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if len(patch) > 0 {
+		var names []string
+		for name := range patch {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return "", nil, utils.NewFieldError(utils.ErrValidation, names[0], "%q field can't be patched", names[0])
+	}
+
+	return newItem, opts, nil
+}
+
+// decodePatchValue decodes a field of the patch, which mustn't be null.
+func decodePatchValue(name string, value json.RawMessage, v interface{}, kind string) error {
+	if isNull(value) || json.Unmarshal(value, v) != nil {
+		return utils.NewFieldError(utils.ErrValidation, name, "%q field has to be %v", name, kind)
+	}
+
+	return nil
+}
+
+func isNull(value json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// The kinds of errors the handlers add to those of utils.
+var (
+	// ErrMalformedRequest means that the body, a parameter or a header can't
+	// be parsed.
+	ErrMalformedRequest = errors.New("malformed request")
+	// ErrUnsupportedMediaType means that the body has the wrong content type.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 problem document every error is written as.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError names the field or parameter of the request an error is about
+// and, for a filter, the 1-based position of the error within it.
+type FieldError struct {
+	Field    string `json:"field"`
+	Detail   string `json:"detail"`
+	Position int    `json:"position,omitempty"`
+}
+
+type problemKind struct {
+	kind   error
+	uri    string
+	title  string
+	status int
+}
+
+var problemKinds = []problemKind{
+	{kind: ErrMalformedRequest, uri: "/problems/malformed-request", title: "Malformed Request", status: http.StatusBadRequest},
+	{kind: utils.ErrInvalidID, uri: "/problems/invalid-id", title: "Invalid ID", status: http.StatusBadRequest},
+	{kind: utils.ErrInvalidQuery, uri: "/problems/invalid-query", title: "Invalid Query", status: http.StatusBadRequest},
+	{kind: utils.ErrNotFound, uri: "/problems/not-found", title: "Not Found", status: http.StatusNotFound},
+	{kind: utils.ErrConflict, uri: "/problems/conflict", title: "Conflict", status: http.StatusConflict},
+	{kind: utils.ErrVersionMismatch, uri: "/problems/version-mismatch", title: "Version Mismatch", status: http.StatusPreconditionFailed},
+	{kind: ErrUnsupportedMediaType, uri: "/problems/unsupported-media-type", title: "Unsupported Media Type", status: http.StatusUnsupportedMediaType},
+	{kind: utils.ErrValidation, uri: "/problems/validation-failed", title: "Validation Failed", status: http.StatusUnprocessableEntity},
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// Is matches the kind of error the problem was written for, so that clients
+// of the API can decode a Problem and compare it with errors.Is.
+func (p *Problem) Is(target error) bool {
+	for _, kind := range problemKinds {
+		if kind.uri == p.Type {
+			return kind.kind == target
+		}
+	}
+
+	return false
+}
+
+// newProblem describes the error; errors of none of the kinds are server
+// errors.
+func newProblem(err error) *Problem {
+	problem := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: err.Error(),
+	}
+
+	for _, kind := range problemKinds {
+		if errors.Is(err, kind.kind) {
+			problem.Type = kind.uri
+			problem.Title = kind.title
+			problem.Status = kind.status
+			break
+		}
+	}
+
+	var e *utils.Error
+	if errors.As(err, &e) && e.Field != "" {
+		problem.Errors = []FieldError{{Field: e.Field, Detail: e.Detail}}
+	}
+
+	var syntaxErr *utils.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		problem.Errors = []FieldError{{Field: "q", Detail: syntaxErr.Message, Position: syntaxErr.Position}}
+	}
+
+	return problem
+}
+
+// writeError writes the error as a problem document with the status of its
+// kind.
+func writeError(writer http.ResponseWriter, err error) {
+	problem := newProblem(err)
+
+	b, err := json.Marshal(problem)
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", problemContentType)
+	writer.WriteHeader(problem.Status)
+	writer.Write(b)
+}
+
+// decodeJSON reads the body into v; a body that isn't valid JSON for v is a
+// malformed request.
+func decodeJSON(request *http.Request, v interface{}) error {
+	b, err := io.ReadAll(request.Body)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(b, v)
+	var e *utils.Error
+	if errors.As(err, &e) {
+		// a field's UnmarshalJSON rejected its value
+		return err
+	}
+	if err != nil {
+		return utils.NewError(ErrMalformedRequest, "%v", err)
+	}
+
+	return nil
+}
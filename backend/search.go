@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// Search returns the items matching "q", most relevant first. Results are
+// paged with "limit" and "offset" only, since a cursor can't hold on to a
+// position in a ranking.
+func Search(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		withNotes, err := getFieldsParam(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		page, _, err := getPageParams(request)
+		if err == nil {
+			err = page.Validate()
+		}
+		if err == nil && page.Cursor != "" {
+			err = utils.NewFieldError(utils.ErrValidation, "cursor", "search results are paged with offset, not cursor")
+		}
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		results, err := store.Search(request.URL.Query().Get("q"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		start := page.Offset
+		if start > len(results) {
+			start = len(results)
+		}
+		end := start + page.Size()
+		if end > len(results) {
+			end = len(results)
+		}
+		results = results[start:end]
+
+		// the highlight shows the part of the notes that matched
+		if !withNotes {
+			for i := range results {
+				results[i].Item.Notes = ""
+			}
+		}
+
+		b, err := json.Marshal(results)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
@@ -5,13 +5,71 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
-func SetHandlers(router *httprouter.Router, itemList *utils.ItemList) {
-	router.GET("/", PrintItems(itemList))
-	router.POST("/create", CreateItem(itemList))
-	router.GET("/read/:id", ReadItem(itemList))
-	router.GET("/read", ReadAll(itemList))
-	router.PUT("/update/:id", UpdateItem(itemList))
-	router.DELETE("/delete/:id", DeleteItem(itemList))
-	router.DELETE("/delete", DeleteAll(itemList))
-	router.GET("/count", Count(itemList))
+func SetHandlers(router *httprouter.Router, lists *utils.Lists) {
+	// the original routes are aliases for the default list
+	store := lists.Default()
+	router.GET("/", PrintItems(store))
+	router.POST("/create", CreateItem(store))
+	router.GET("/read/:id", ReadItem(store))
+	router.GET("/read", ReadAll(store))
+	router.GET("/search", Search(store))
+	router.PUT("/update/:id", UpdateItem(store))
+	router.PATCH("/items/:id", PatchItem(store))
+	router.GET("/items/:id/history", ReadHistory(store))
+	router.POST("/items/:id/move", MoveItem(store))
+	router.POST("/complete/:id", CompleteItem(store))
+	router.POST("/reopen/:id", ReopenItem(store))
+	router.DELETE("/delete/:id", DeleteItem(store))
+	router.DELETE("/delete", DeleteAll(store))
+	router.GET("/count", Count(store))
+	router.GET("/overdue", Overdue(store))
+	router.GET("/due", DueItems(store))
+	router.POST("/items/:id/tags", AddTags(store))
+	router.DELETE("/items/:id/tags/:tag", RemoveTag(store))
+	router.GET("/tags", ListTags(store))
+	router.POST("/items/:id/blockers", AddBlocker(store))
+	router.DELETE("/items/:id/blockers/:blocker", RemoveBlocker(store))
+	router.GET("/ready", Ready(store))
+	router.GET("/trash", ReadTrash(store))
+	router.POST("/trash/:id/restore", RestoreItem(store))
+	router.POST("/undo", Undo(store))
+	router.POST("/redo", Redo(store))
+
+	router.GET("/v1/items", ReadAll(store))
+	router.POST("/v1/items", V1CreateItem(store))
+	router.GET("/v1/items/:id", ReadItem(store))
+	router.PUT("/v1/items/:id", UpdateItem(store))
+	router.PATCH("/v1/items/:id", PatchItem(store))
+	router.DELETE("/v1/items/:id", V1DeleteItem(store))
+
+	router.GET("/lists", ReadLists(lists))
+	router.POST("/lists", CreateList(lists))
+	router.DELETE("/lists/:list", DeleteList(lists))
+
+	router.GET("/lists/:list", inList(lists, PrintItems))
+	router.POST("/lists/:list/items", inList(lists, CreateItem))
+	router.GET("/lists/:list/items/:id", inList(lists, ReadItem))
+	router.GET("/lists/:list/items", inList(lists, ReadAll))
+	router.GET("/lists/:list/search", inList(lists, Search))
+	router.PUT("/lists/:list/items/:id", inList(lists, UpdateItem))
+	router.PATCH("/lists/:list/items/:id", inList(lists, PatchItem))
+	router.GET("/lists/:list/items/:id/history", inList(lists, ReadHistory))
+	router.POST("/lists/:list/items/:id/move", inList(lists, MoveItem))
+	router.POST("/lists/:list/items/:id/complete", inList(lists, CompleteItem))
+	router.POST("/lists/:list/items/:id/reopen", inList(lists, ReopenItem))
+	router.DELETE("/lists/:list/items/:id", inList(lists, DeleteItem))
+	router.DELETE("/lists/:list/items", inList(lists, DeleteAll))
+	router.GET("/lists/:list/count", inList(lists, Count))
+	router.GET("/lists/:list/overdue", inList(lists, Overdue))
+	router.GET("/lists/:list/due", inList(lists, DueItems))
+	router.POST("/lists/:list/items/:id/tags", inList(lists, AddTags))
+	router.DELETE("/lists/:list/items/:id/tags/:tag", inList(lists, RemoveTag))
+	router.GET("/lists/:list/tags", inList(lists, ListTags))
+	router.POST("/lists/:list/items/:id/blockers", inList(lists, AddBlocker))
+	router.DELETE("/lists/:list/items/:id/blockers/:blocker", inList(lists, RemoveBlocker))
+	router.GET("/lists/:list/ready", inList(lists, Ready))
+	router.GET("/lists/:list/trash", inList(lists, ReadTrash))
+	router.POST("/lists/:list/trash/:id/restore", inList(lists, RestoreItem))
+	router.POST("/lists/:list/undo", inList(lists, Undo))
+	router.POST("/lists/:list/redo", inList(lists, Redo))
 }
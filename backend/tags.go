@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type TagsRequestBody struct {
+	Tags []string `json:"tags"`
+}
+
+func AddTags(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		reqBody, err := parseTagsRequestBody(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.AddTags(id, reqBody.Tags...)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func RemoveTag(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		item, err := store.RemoveTag(id, ps.ByName("tag"))
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Write(b)
+	})
+}
+
+func ListTags(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		tags := store.Tags()
+
+		b, err := json.Marshal(tags)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
+
+// getTagParams returns the normalized "tag" query parameters and how they
+// are combined.
+func getTagParams(request *http.Request) ([]string, utils.TagMode, error) {
+	var tags []string
+	for _, tag := range request.URL.Query()["tag"] {
+		normalized, err := utils.NormalizeTag(tag)
+		if err != nil {
+			return nil, "", err
+		}
+
+		tags = append(tags, normalized)
+	}
+
+	mode, err := utils.ParseTagMode(request.URL.Query().Get("tag_mode"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tags, mode, nil
+}
+
+func parseTagsRequestBody(request *http.Request) (*TagsRequestBody, error) {
+	var r TagsRequestBody
+	err := decodeJSON(request, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.Tags) == 0 {
+		return nil, utils.NewFieldError(utils.ErrValidation, "tags", "\"tags\" field in body was not populated")
+	}
+
+	return &r, nil
+}
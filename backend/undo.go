@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func Undo(store utils.Store) httprouter.Handle {
+	return writeOperation(store.Undo)
+}
+
+func Redo(store utils.Store) httprouter.Handle {
+	return writeOperation(store.Redo)
+}
+
+// writeOperation responds with the description of the operation undone or
+// redone by fn.
+func writeOperation(fn func() (utils.Operation, error)) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		op, err := fn()
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(op)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.Write(b)
+	})
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// The /v1 routes expose the default list's items as a resource: creating
+// an item answers 201 Created and deleting it 204 No Content, unlike the
+// original routes, which answer 200 with the item for compatibility.
+
+func V1CreateItem(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		reqBody, err := parseRequestBody(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		opts, err := reqBody.itemOptions()
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		opts = append(opts, getActor(request)...)
+
+		item, err := store.CreateItem(reqBody.Item, opts...)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		setETag(writer, item)
+		writer.Header().Set("Location", fmt.Sprintf("/v1/items/%v", item.ID))
+		writer.WriteHeader(http.StatusCreated)
+		writer.Write(b)
+	})
+}
+
+// V1DeleteItem moves the item, or with ?cascade=true the item and its
+// subtasks, to the trash and answers 204 No Content.
+func V1DeleteItem(store utils.Store) httprouter.Handle {
+	return httprouter.Handle(func(writer http.ResponseWriter, request *http.Request, ps httprouter.Params) {
+		id, err := getID(ps)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		cascade, err := getBoolParam(request, "cascade")
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		preconditions, err := getIfMatch(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		opts := append(preconditions, getActor(request)...)
+
+		if cascade {
+			_, err = store.DeleteTree(id, opts...)
+		} else {
+			_, err = store.DeleteItem(id, opts...)
+		}
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -3,34 +3,99 @@ package main
 import (
 	"TodoApplication/backend"
 	"TodoApplication/utils"
+	"flag"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"time"
 )
 
 func main() {
+	dataPath := flag.String("data", "", "path the list is persisted to (in-memory if empty)")
+	storeType := flag.String("store", "json", "how the list is persisted to -data: \"json\" rewrites a single file, \"wal\" keeps a write-ahead log in a directory, \"sqlite\" uses an SQLite database")
+	compactEvery := flag.Int("compact-every", utils.DefaultCompactEvery, "number of write-ahead log records after which the log is compacted")
+	undoDepth := flag.Int("undo-depth", utils.DefaultUndoDepth, "number of operations on a list that can be undone (0 disables undo)")
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted items are kept in the trash before they are purged (0 keeps them forever)")
+	flag.Parse()
+
+	if *undoDepth < 0 {
+		log.Fatalf("-undo-depth (%v) can't be negative", *undoDepth)
+	}
+
 	router := httprouter.New()
-	itemList := utils.NewItemList()
 	port := 9000
 
-	if len(os.Args) >= 2 {
-		newPort, err := strconv.Atoi(os.Args[1])
+	if flag.NArg() >= 1 {
+		newPort, err := strconv.Atoi(flag.Arg(0))
 		if err != nil {
-			log.Fatalf("argument for port (%v) has to be an integer", os.Args[1])
+			log.Fatalf("argument for port (%v) has to be an integer", flag.Arg(0))
 		}
 
 		port = newPort
 	}
 
-	backend.SetHandlers(router, itemList)
+	lists, err := newLists(*dataPath, *storeType, *compactEvery)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lists.SetUndoDepth(*undoDepth)
+
+	if *trashRetention > 0 {
+		utils.StartJanitor(lists, *trashRetention)
+	}
+
+	backend.SetHandlers(router, lists)
 
 	logrus.Infof("server starting at port %v", port)
-	err := http.ListenAndServe(fmt.Sprintf(":%v", port), router)
+	err = http.ListenAndServe(fmt.Sprintf(":%v", port), router)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// newLists opens the default list at dataPath and the named lists in the
+// dataPath.lists directory next to it.
+func newLists(dataPath string, storeType string, compactEvery int) (*utils.Lists, error) {
+	if dataPath == "" {
+		return utils.NewLists(utils.NewItemList(), utils.MemoryLists{})
+	}
+
+	logrus.Infof("persisting to-do lists to %v (%v)", dataPath, storeType)
+
+	var openStore func(path string) (utils.Store, error)
+	var ext string
+
+	switch storeType {
+	case "json":
+		ext = ".json"
+		openStore = func(path string) (utils.Store, error) {
+			return utils.NewFileStore(path)
+		}
+	case "wal":
+		openStore = func(path string) (utils.Store, error) {
+			return utils.NewWALStore(path, compactEvery)
+		}
+	case "sqlite":
+		ext = ".db"
+		openStore = func(path string) (utils.Store, error) {
+			return utils.NewSQLStore(path)
+		}
+	default:
+		return nil, fmt.Errorf("unknown store type (%v)", storeType)
+	}
+
+	defaultList, err := openStore(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.NewLists(defaultList, utils.DirLists{
+		Dir:       dataPath + ".lists",
+		Ext:       ext,
+		OpenStore: openStore,
+	})
+}
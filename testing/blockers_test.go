@@ -0,0 +1,165 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestAddBlocker(t *testing.T) {
+	testTable := []struct {
+		name             string
+		id               int
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "new blocker",
+			id:               1,
+			body:             `{"blocker_id":3}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "a", Version: 3, BlockedBy: []int{2, 3}},
+			expectedCode:     200,
+		},
+		{
+			name:             "existing blocker",
+			id:               1,
+			body:             `{"blocker_id":2}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "a", Version: 2, BlockedBy: []int{2}},
+			expectedCode:     200,
+		},
+		{
+			name:          "itself",
+			id:            1,
+			body:          `{"blocker_id":1}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "cycle",
+			id:            2,
+			body:          `{"blocker_id":1}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "nonexistent blocker",
+			id:            1,
+			body:          `{"blocker_id":4}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "no blocker",
+			id:            1,
+			body:          `{}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("a")
+			itemList.CreateItem("b")
+			itemList.CreateItem("c")
+			itemList.AddBlocker(1, 2)
+
+			item, code, err := itemRequest(t, router, http.MethodPost, fmt.Sprintf("/items/%v/blockers", testCase.id), bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestAddBlocker_Blocks(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("a")
+	itemList.CreateItem("b")
+	itemList.CreateItem("c")
+
+	_, _, err := itemRequest(t, router, http.MethodPost, "/items/1/blockers", bytes.NewBufferString(`{"blocker_id":3}`))
+	require.Nil(t, err)
+	_, _, err = itemRequest(t, router, http.MethodPost, "/items/2/blockers", bytes.NewBufferString(`{"blocker_id":3}`))
+	require.Nil(t, err)
+
+	item, _, err := readItem(t, router, 3)
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 3, Item: "c", Version: 3, Blocks: []int{1, 2}}, item)
+
+	// a cycle through several items is rejected too
+	_, code, err := itemRequest(t, router, http.MethodPost, "/items/3/blockers", bytes.NewBufferString(`{"blocker_id":2}`))
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+}
+
+func TestRemoveBlocker(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("a")
+	itemList.CreateItem("b")
+	itemList.AddBlocker(1, 2)
+
+	item, code, err := itemRequest(t, router, http.MethodDelete, "/items/1/blockers/2", nil)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "a", Version: 3}, item)
+
+	item, _, err = readItem(t, router, 2)
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 2, Item: "b", Version: 3}, item)
+
+	_, code, err = itemRequest(t, router, http.MethodDelete, "/items/1/blockers/2", nil)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+}
+
+func TestReady(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("blocked by an open item")
+	itemList.CreateItem("open blocker")
+	itemList.CreateItem("blocked by a done item")
+	itemList.CreateItem("done blocker")
+	itemList.CreateItem("done")
+	itemList.AddBlocker(1, 2)
+	itemList.AddBlocker(3, 4)
+	itemList.CompleteItem(4)
+	itemList.CompleteItem(5)
+
+	items, code, err := getItems(t, router, "/ready")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+
+	ids := []int{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{2, 3}, ids)
+}
+
+func TestDeleteItem_Blockers(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("a")
+	itemList.CreateItem("b")
+	itemList.CreateItem("c")
+	itemList.AddBlocker(1, 2)
+	itemList.AddBlocker(2, 3)
+
+	_, _, err := deleteItem(t, router, 2)
+	require.Nil(t, err)
+
+	items, _ := readItems(t, router)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "a", Version: 3}, {ID: 3, Item: "c", Version: 3}}, items)
+}
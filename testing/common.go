@@ -8,15 +8,33 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
-func setup() (*httprouter.Router, *utils.ItemList) {
+// newLists creates the lists the handlers are tested against; TestMain runs
+// the suite once for every store in testStores.
+var newLists = func() (*utils.Lists, error) {
+	return utils.NewLists(utils.NewItemList(), utils.MemoryLists{})
+}
+
+func setup() (*httprouter.Router, utils.Store) {
+	router, lists := setupLists()
+	return router, lists.Default()
+}
+
+func setupLists() (*httprouter.Router, *utils.Lists) {
 	router := httprouter.New()
-	itemList := utils.NewItemList()
-	backend.SetHandlers(router, itemList)
-	return router, itemList
+	lists, err := newLists()
+	if err != nil {
+		panic(err)
+	}
+	backend.SetHandlers(router, lists)
+	return router, lists
 }
 
 func createValidRequestBody(item string) *bytes.Buffer {
-	b, _ := json.Marshal(backend.RequestBody{Item: item})
+	return createRequestBody(backend.RequestBody{Item: item})
+}
+
+func createRequestBody(body backend.RequestBody) *bytes.Buffer {
+	b, _ := json.Marshal(body)
 	return bytes.NewBuffer(b)
 }
 
@@ -28,3 +46,15 @@ func createInvalidRequestBody() *bytes.Buffer {
 	b, _ := json.Marshal(invalidRequestBody{Noop: "123"})
 	return bytes.NewBuffer(b)
 }
+
+// problemOf decodes the problem document of an error response, which
+// errors.Is matches with the kind of the error.
+func problemOf(b []byte) error {
+	var problem backend.Problem
+	err := json.Unmarshal(b, &problem)
+	if err != nil {
+		return err
+	}
+
+	return &problem
+}
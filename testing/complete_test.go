@@ -0,0 +1,182 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompleteItem_IDValidity(t *testing.T) {
+	testTable := []struct {
+		name          string
+		id            int
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:          "valid id",
+			id:            2,
+			expectedError: nil,
+			expectedCode:  200,
+		},
+		{
+			name:          "invalid id below 1",
+			id:            0,
+			expectedError: utils.ErrInvalidID,
+			expectedCode:  400,
+		},
+		{
+			name:          "nonexistent id",
+			id:            3,
+			expectedError: utils.ErrNotFound,
+			expectedCode:  404,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("hello")
+			itemList.CreateItem("world")
+			_, code, err := completeItem(t, router, testCase.id)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestCompleteAndReopenItem(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc")
+	itemList.CreateItem("def")
+
+	item, code, err := completeItem(t, router, 2)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.True(t, item.Done)
+	require.NotNil(t, item.CompletedAt)
+
+	resp, _ := printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n1. [ ] abc\n2. [x] def\n", resp)
+
+	// completing again keeps the original completion time
+	again, _, err := completeItem(t, router, 2)
+	require.Nil(t, err)
+	assert.Equal(t, item, again)
+
+	item, code, err = reopenItem(t, router, 2)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 2, Item: "def", Version: 3}, item)
+
+	resp, _ = printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n1. [ ] abc\n2. [ ] def\n", resp)
+}
+
+func TestReadAll_Status(t *testing.T) {
+	testTable := []struct {
+		name          string
+		status        string
+		expectedIDs   []int
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "no status",
+			status:       "",
+			expectedIDs:  []int{1, 2, 3},
+			expectedCode: 200,
+		},
+		{
+			name:         "all",
+			status:       "all",
+			expectedIDs:  []int{1, 2, 3},
+			expectedCode: 200,
+		},
+		{
+			name:         "open",
+			status:       "open",
+			expectedIDs:  []int{1, 3},
+			expectedCode: 200,
+		},
+		{
+			name:         "done",
+			status:       "done",
+			expectedIDs:  []int{2},
+			expectedCode: 200,
+		},
+		{
+			name:          "invalid status",
+			status:        "finished",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def")
+			itemList.CreateItem("123")
+			itemList.CompleteItem(2)
+
+			items, code, err := readItemsWithQuery(t, router, "status="+testCase.status)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+
+			ids := []int{}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			if testCase.expectedError == nil {
+				assert.Equal(t, testCase.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func completeItem(t *testing.T, router *httprouter.Router, id int) (utils.ItemAndID, int, error) {
+	return postItemAction(t, router, fmt.Sprintf("/complete/%v", id))
+}
+
+func reopenItem(t *testing.T, router *httprouter.Router, id int) (utils.ItemAndID, int, error) {
+	return postItemAction(t, router, fmt.Sprintf("/reopen/%v", id))
+}
+
+func postItemAction(t *testing.T, router *httprouter.Router, path string) (utils.ItemAndID, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
+	var resp utils.ItemAndID
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
+
+func readItemsWithQuery(t *testing.T, router *httprouter.Router, query string) ([]utils.ItemAndID, int, error) {
+	return getItems(t, router, "/read?"+query)
+}
@@ -4,7 +4,6 @@ import (
 	"TodoApplication/utils"
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +19,7 @@ func TestCreateItem_ValidBody(t *testing.T) {
 	response, code, err := createItemValidBody(t, router, "abc")
 
 	assert.Nil(t, err)
-	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc"}, response)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc", Version: 1}, response)
 	assert.Equal(t, 200, code)
 }
 
@@ -29,8 +28,8 @@ func TestCreateItem_InvalidBody(t *testing.T) {
 
 	_, code, err := createItemInvalidBody(t, router)
 
-	assert.Equal(t, "\"item\" field in body was not populated", err.Error())
-	assert.Equal(t, 400, code)
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
 }
 
 func createItemValidBody(t *testing.T, router *httprouter.Router, item string) (utils.ItemAndID, int, error) {
@@ -51,11 +50,13 @@ func createItem(t *testing.T, router *httprouter.Router, body *bytes.Buffer) (ut
 	b, err := io.ReadAll(w.Body)
 	require.Nil(t, err)
 
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
 	var resp utils.ItemAndID
 	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return utils.ItemAndID{}, code, fmt.Errorf(string(b))
-	}
+	require.Nil(t, err)
 
 	return resp, code, nil
 }
@@ -16,26 +16,32 @@ func TestCrud(t *testing.T) {
 	require.Equal(t, []utils.ItemAndID{}, items)
 
 	item, _, _ := createItemValidBody(t, router, "abc")
-	require.Equal(t, utils.ItemAndID{Item: "abc", ID: 1}, item)
+	require.Equal(t, utils.ItemAndID{Item: "abc", ID: 1, Version: 1}, item)
 
 	item, _, err := readItem(t, router, 1)
 	require.Nil(t, err)
-	require.Equal(t, utils.ItemAndID{Item: "abc", ID: 1}, item)
+	require.Equal(t, utils.ItemAndID{Item: "abc", ID: 1, Version: 1}, item)
 
 	item, _, err = updateItemValidBody(t, router, 1, "123")
 	require.Nil(t, err)
-	require.Equal(t, utils.ItemAndID{Item: "123", ID: 1}, item)
+	require.Equal(t, utils.ItemAndID{Item: "123", ID: 1, Version: 2}, item)
 
 	itemsStr, _ = printItems(t, router)
-	require.Equal(t, "TO-DO LIST\n----------\n1. 123\n", itemsStr)
+	require.Equal(t, "TO-DO LIST\n----------\n1. [ ] 123\n", itemsStr)
 
 	items, _ = readItems(t, router)
-	require.Equal(t, []utils.ItemAndID{{Item: "123", ID: 1}}, items)
+	require.Equal(t, []utils.ItemAndID{{Item: "123", ID: 1, Version: 2}}, items)
 
 	item, _, err = deleteItem(t, router, 1)
 	require.Nil(t, err)
-	require.Equal(t, utils.ItemAndID{Item: "123", ID: 1}, item)
+	require.Equal(t, utils.ItemAndID{Item: "123", ID: 1, Version: 2}, item)
 
 	items, _ = readItems(t, router)
 	require.Equal(t, []utils.ItemAndID{}, items)
+
+	item, _, _ = createItemValidBody(t, router, "def")
+	require.Equal(t, utils.ItemAndID{Item: "def", ID: 2, Version: 1}, item)
+
+	_, _, err = readItem(t, router, 1)
+	require.NotNil(t, err)
 }
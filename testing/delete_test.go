@@ -29,14 +29,14 @@ func TestDeleteItem_InvalidID(t *testing.T) {
 		{
 			name:          "invalid id below 1",
 			id:            0,
-			expectedError: fmt.Errorf("id is less than 1"),
+			expectedError: utils.ErrInvalidID,
 			expectedCode:  400,
 		},
 		{
-			name:          "invalid id above size",
+			name:          "nonexistent id",
 			id:            3,
-			expectedError: fmt.Errorf("id (3) is more than the number of items (2)"),
-			expectedCode:  400,
+			expectedError: utils.ErrNotFound,
+			expectedCode:  404,
 		},
 	}
 
@@ -48,7 +48,7 @@ func TestDeleteItem_InvalidID(t *testing.T) {
 			itemList.CreateItem("world")
 			_, code, err := deleteItem(t, router, testCase.id)
 
-			assert.Equal(t, testCase.expectedError, err)
+			assert.ErrorIs(t, err, testCase.expectedError)
 			assert.Equal(t, testCase.expectedCode, code)
 		})
 	}
@@ -61,7 +61,7 @@ func TestDeleteAll(t *testing.T) {
 	itemList.CreateItem("def")
 
 	deleteItems, code := deleteAll(t, router)
-	require.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc"}, {ID: 2, Item: "def"}}, deleteItems)
+	require.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "def", Version: 1}}, deleteItems)
 	require.Equal(t, 200, code)
 
 	assert.Equal(t, len(itemList.ReadAll()), 0)
@@ -77,11 +77,13 @@ func deleteItem(t *testing.T, router *httprouter.Router, id int) (utils.ItemAndI
 	b, err := io.ReadAll(w.Body)
 	require.Nil(t, err)
 
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
 	var resp utils.ItemAndID
 	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return utils.ItemAndID{}, code, fmt.Errorf(string(b))
-	}
+	require.Nil(t, err)
 
 	return resp, code, nil
 }
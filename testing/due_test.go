@@ -0,0 +1,193 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	pastDue   = time.Date(2000, 1, 1, 9, 0, 0, 0, time.UTC)
+	futureDue = time.Date(2100, 1, 1, 9, 0, 0, 0, time.UTC)
+)
+
+func TestCreateItem_Due(t *testing.T) {
+	testTable := []struct {
+		name             string
+		due              string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "no due date",
+			due:              "",
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1},
+			expectedCode:     200,
+		},
+		{
+			name:             "utc due date",
+			due:              "2100-01-01T09:00:00Z",
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1, Due: &futureDue},
+			expectedCode:     200,
+		},
+		{
+			name:             "due date with offset",
+			due:              "2100-01-01T11:00:00+02:00",
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1, Due: &futureDue},
+			expectedCode:     200,
+		},
+		{
+			name:          "invalid due date",
+			due:           "tomorrow",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, _ := setup()
+
+			response, code, err := createItem(t, router, createRequestBody(backend.RequestBody{Item: "abc", Due: testCase.due}))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, response)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestUpdateItem_Due(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc", utils.WithDue(pastDue))
+
+	// leaving out the due date keeps it
+	item, _, err := updateItemValidBody(t, router, 1, "def")
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "def", Version: 2, Due: &pastDue}, item)
+
+	item, _, err = updateItem(t, router, 1, createRequestBody(backend.RequestBody{Item: "def", Due: "2100-01-01T09:00:00Z"}))
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "def", Version: 3, Due: &futureDue}, item)
+
+	_, code, err := updateItem(t, router, 1, createRequestBody(backend.RequestBody{Item: "def", Due: "2100-01-01"}))
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+}
+
+func TestOverdue(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("past", utils.WithDue(pastDue))
+	itemList.CreateItem("future", utils.WithDue(futureDue))
+	itemList.CreateItem("no due date")
+	itemList.CreateItem("past but done", utils.WithDue(pastDue))
+	itemList.CompleteItem(4)
+
+	items, code, err := getItems(t, router, "/overdue")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "past", Version: 1, Due: &pastDue}}, items)
+
+	resp, _ := printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n"+
+		"1. [ ] past (due 2000-01-01T09:00:00Z) OVERDUE\n"+
+		"2. [ ] future (due 2100-01-01T09:00:00Z)\n"+
+		"3. [ ] no due date\n"+
+		"4. [x] past but done (due 2000-01-01T09:00:00Z)\n", resp)
+}
+
+func TestDueItems(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "no bounds",
+			query:        "",
+			expectedIDs:  []int{1, 2, 4},
+			expectedCode: 200,
+		},
+		{
+			name:         "before",
+			query:        "before=2050-01-01T00:00:00Z",
+			expectedIDs:  []int{1, 4},
+			expectedCode: 200,
+		},
+		{
+			name:         "after",
+			query:        "after=2050-01-01T00:00:00Z",
+			expectedIDs:  []int{2},
+			expectedCode: 200,
+		},
+		{
+			name:         "before and after",
+			query:        "after=1999-01-01T00:00:00Z&before=2000-06-01T00:00:00Z",
+			expectedIDs:  []int{1, 4},
+			expectedCode: 200,
+		},
+		{
+			name:          "invalid bound",
+			query:         "before=soon",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("abc", utils.WithDue(pastDue))
+			itemList.CreateItem("def", utils.WithDue(futureDue))
+			itemList.CreateItem("123")
+			itemList.CreateItem("456", utils.WithDue(pastDue))
+
+			items, code, err := getItems(t, router, "/due?"+testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+			if testCase.expectedError == nil {
+				ids := []int{}
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func getItems(t *testing.T, router *httprouter.Router, path string) ([]utils.ItemAndID, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return nil, code, problemOf(b)
+	}
+
+	var resp []utils.ItemAndID
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
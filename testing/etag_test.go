@@ -0,0 +1,216 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"bytes"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	router, _ := setup()
+
+	w := etagRequest(router, http.MethodPost, "/create", nil, `{"item":"abc"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = etagRequest(router, http.MethodPut, "/update/1", nil, `{"item":"def"}`)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = etagRequest(router, http.MethodPost, "/complete/1", nil, "")
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = etagRequest(router, http.MethodGet, "/read/1", nil, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestReadItem_IfNoneMatch(t *testing.T) {
+	testTable := []struct {
+		name         string
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{name: "current version", ifNoneMatch: `"2"`, expectedCode: 304},
+		{name: "weak current version", ifNoneMatch: `W/"2"`, expectedCode: 304},
+		{name: "list", ifNoneMatch: `"1", "2"`, expectedCode: 304},
+		{name: "any", ifNoneMatch: "*", expectedCode: 304},
+		{name: "old version", ifNoneMatch: `"1"`, expectedCode: 200},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+			itemList.UpdateItem(1, "def")
+
+			w := etagRequest(router, http.MethodGet, "/read/1", map[string]string{"If-None-Match": testCase.ifNoneMatch}, "")
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			if testCase.expectedCode == 304 {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateItem_IfMatch(t *testing.T) {
+	testTable := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		ifMatch       string
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "update at current version",
+			method:       http.MethodPut,
+			path:         "/update/1",
+			body:         `{"item":"ghi"}`,
+			ifMatch:      `"2"`,
+			expectedCode: 200,
+		},
+		{
+			name:         "update with any version",
+			method:       http.MethodPut,
+			path:         "/update/1",
+			body:         `{"item":"ghi"}`,
+			ifMatch:      "*",
+			expectedCode: 200,
+		},
+		{
+			name:          "stale update",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `"1"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:          "unknown etag",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `"abc"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:         "list of etags",
+			method:       http.MethodPut,
+			path:         "/update/1",
+			body:         `{"item":"ghi"}`,
+			ifMatch:      `"1", "2"`,
+			expectedCode: 200,
+		},
+		{
+			name:          "stale list of etags",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `"1","3",`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:          "weak etag",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `W/"2"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:          "malformed etag",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `2`,
+			expectedError: backend.ErrMalformedRequest,
+			expectedCode:  400,
+		},
+		{
+			name:          "unterminated etag",
+			method:        http.MethodPut,
+			path:          "/update/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `"1", "2`,
+			expectedError: backend.ErrMalformedRequest,
+			expectedCode:  400,
+		},
+		{
+			name:          "stale patch",
+			method:        http.MethodPatch,
+			path:          "/items/1",
+			body:          `{"item":"ghi"}`,
+			ifMatch:       `"1"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:          "stale delete",
+			method:        http.MethodDelete,
+			path:          "/delete/1",
+			ifMatch:       `"1"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+		{
+			name:          "stale cascading delete",
+			method:        http.MethodDelete,
+			path:          "/delete/1?cascade=true",
+			ifMatch:       `"3"`,
+			expectedError: utils.ErrVersionMismatch,
+			expectedCode:  412,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+			itemList.UpdateItem(1, "def")
+
+			headers := map[string]string{"If-Match": testCase.ifMatch}
+			if testCase.method == http.MethodPatch {
+				headers["Content-Type"] = "application/merge-patch+json"
+			}
+			w := etagRequest(router, testCase.method, testCase.path, headers, testCase.body)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			if testCase.expectedError != nil {
+				b, err := io.ReadAll(w.Body)
+				require.Nil(t, err)
+				assert.ErrorIs(t, problemOf(b), testCase.expectedError)
+
+				// the item is left as it was
+				item, err := itemList.ReadItem(1)
+				require.Nil(t, err)
+				assert.Equal(t, utils.ItemAndID{ID: 1, Item: "def", Version: 2}, item)
+			}
+		})
+	}
+}
+
+func etagRequest(router *httprouter.Router, method string, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	router.ServeHTTP(w, req)
+
+	return w
+}
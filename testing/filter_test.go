@@ -0,0 +1,115 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestReadAll_Filter(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "text",
+			query:        "q=" + url.QueryEscape("milk"),
+			expectedIDs:  []int{1},
+			expectedCode: 200,
+		},
+		{
+			name:         "combined",
+			query:        "q=" + url.QueryEscape("tag:home and (priority>=high or due<2024-06-02)"),
+			expectedIDs:  []int{1, 2},
+			expectedCode: 200,
+		},
+		{
+			name:         "negated",
+			query:        "q=" + url.QueryEscape("not tag:home"),
+			expectedIDs:  []int{3},
+			expectedCode: 200,
+		},
+		{
+			name:         "with the other parameters",
+			query:        "status=open&q=" + url.QueryEscape("priority>none"),
+			expectedIDs:  []int{2},
+			expectedCode: 200,
+		},
+		{
+			name:         "sorted by several fields",
+			query:        "sort=" + url.QueryEscape("-done,due"),
+			expectedIDs:  []int{3, 1, 2},
+			expectedCode: 200,
+		},
+		{
+			name:          "syntax error",
+			query:         "q=" + url.QueryEscape("tag:home or"),
+			expectedError: utils.ErrInvalidQuery,
+			expectedCode:  400,
+		},
+		{
+			name:          "unknown sort field",
+			query:         "sort=" + url.QueryEscape("due,colour"),
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			june := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+			itemList.CreateItem("Buy milk", utils.WithTags("home"), utils.WithDue(june))
+			itemList.CreateItem("Mow the lawn", utils.WithTags("home"), utils.WithPriority(utils.PriorityHigh))
+			itemList.CreateItem("Fix the pager", utils.WithPriority(utils.PriorityUrgent))
+			_, err := itemList.CompleteItem(3)
+			require.Nil(t, err)
+
+			items, code, err := readItemsWithQuery(t, router, testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+			if testCase.expectedError == nil {
+				ids := []int{}
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestReadAll_FilterErrorPosition(t *testing.T) {
+	router, _ := setup()
+
+	w := etagRequest(router, http.MethodGet, "/read?q="+url.QueryEscape("tag:home and prio>high"), nil, "")
+
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"type":"/problems/invalid-query","title":"Invalid Query","status":400,"detail":"filter has an error at position 14: unknown field (prio), has to be one of text, status, tag, due or priority","errors":[{"field":"q","detail":"unknown field (prio), has to be one of text, status, tag, due or priority","position":14}]}`, w.Body.String())
+}
+
+func TestReadAll_FilterPages(t *testing.T) {
+	router, itemList := setup()
+	for _, item := range []string{"a1", "b", "a2", "a3"} {
+		itemList.CreateItem(item)
+	}
+
+	first, _, code, err := readPage(t, router, "/read?limit=2&sort=-id&q=a")
+	require.Nil(t, err)
+	require.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 4, Item: "a3", Version: 1}, {ID: 3, Item: "a2", Version: 1}}, first.Items)
+
+	second, link, code, err := readPage(t, router, "/read?limit=2&sort=-id&q=a&cursor="+first.NextCursor)
+	require.Nil(t, err)
+	require.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "a1", Version: 1}}, second.Items)
+	assert.Equal(t, `</read?limit=2&q=a&sort=-id>; rel="first"`, link)
+}
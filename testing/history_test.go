@@ -0,0 +1,105 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	router, _ := setup()
+
+	w := etagRequest(router, http.MethodPost, "/create", map[string]string{"X-Actor": "alice"}, `{"item":"abc"}`)
+	require.Equal(t, 200, w.Code)
+	w = etagRequest(router, http.MethodPut, "/update/1", map[string]string{"X-Actor": "bob"}, `{"item":"def"}`)
+	require.Equal(t, 200, w.Code)
+	w = etagRequest(router, http.MethodDelete, "/delete/1", nil, "")
+	require.Equal(t, 200, w.Code)
+
+	revisions, code, err := readHistory(t, router, "/items/1/history")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	require.Equal(t, 3, len(revisions))
+
+	// revision times are set by the server, so leave them out of the comparison
+	for i := range revisions {
+		revisions[i].At = time.Time{}
+	}
+	assert.Equal(t, []utils.Revision{
+		{ItemID: 1, Op: utils.RevisionCreated, Actor: "alice", New: &utils.ItemAndID{ID: 1, Item: "abc", Version: 1}},
+		{ItemID: 1, Op: utils.RevisionUpdated, Actor: "bob", Old: &utils.ItemAndID{ID: 1, Item: "abc", Version: 1}, New: &utils.ItemAndID{ID: 1, Item: "def", Version: 2}},
+		{ItemID: 1, Op: utils.RevisionDeleted, Old: &utils.ItemAndID{ID: 1, Item: "def", Version: 2}},
+	}, revisions)
+
+	_, code, err = readHistory(t, router, "/items/2/history")
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+}
+
+func TestReadAll_AsOf(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc")
+	itemList.CreateItem("def")
+	afterCreate := time.Now()
+	itemList.UpdateItem(1, "123")
+	itemList.DeleteItem(2)
+
+	items, code, err := readItemsWithQuery(t, router, "as_of="+url.QueryEscape(afterCreate.Format(time.RFC3339Nano)))
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "def", Version: 1}}, items)
+
+	items, _, err = readItemsWithQuery(t, router, "as_of="+url.QueryEscape(time.Now().Format(time.RFC3339Nano)))
+	require.Nil(t, err)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "123", Version: 2}}, items)
+
+	_, code, err = readItemsWithQuery(t, router, "as_of=yesterday")
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+}
+
+func TestReadAll_AsOfAfterMove(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc")
+	itemList.CreateItem("def")
+	itemList.CreateItem("ghi")
+	_, _, err := itemRequest(t, router, http.MethodPost, "/items/3/move", bytes.NewBufferString(`{"position":1}`))
+	require.Nil(t, err)
+
+	items, code, err := readItemsWithQuery(t, router, "as_of="+url.QueryEscape(time.Now().Add(time.Second).Format(time.RFC3339Nano)))
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []int{3, 1, 2}, []int{items[0].ID, items[1].ID, items[2].ID})
+}
+
+func readHistory(t *testing.T, router *httprouter.Router, path string) ([]utils.Revision, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return nil, code, problemOf(b)
+	}
+
+	var resp []utils.Revision
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
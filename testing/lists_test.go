@@ -0,0 +1,191 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateList(t *testing.T) {
+	testTable := []struct {
+		name             string
+		body             string
+		expectedResponse utils.ListInfo
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "valid name",
+			body:             `{"name":"release-checklist"}`,
+			expectedResponse: utils.ListInfo{Name: "release-checklist"},
+			expectedCode:     200,
+		},
+		{
+			name:          "existing list",
+			body:          `{"name":"sprint"}`,
+			expectedError: utils.ErrConflict,
+			expectedCode:  409,
+		},
+		{
+			name:          "default list",
+			body:          `{"name":"default"}`,
+			expectedError: utils.ErrConflict,
+			expectedCode:  409,
+		},
+		{
+			name:          "invalid name",
+			body:          `{"name":"../sprint"}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "no name",
+			body:          `{}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, lists := setupLists()
+			lists.Create("sprint")
+
+			list, code, err := listRequest(t, router, http.MethodPost, "/lists", bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, list)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestNestedItemRoutes(t *testing.T) {
+	router, lists := setupLists()
+
+	_, _, err := listRequest(t, router, http.MethodPost, "/lists", bytes.NewBufferString(`{"name":"sprint"}`))
+	require.Nil(t, err)
+
+	item, code, err := itemRequest(t, router, http.MethodPost, "/lists/sprint/items", createValidRequestBody("abc"))
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc", Version: 1}, item)
+
+	item, _, err = itemRequest(t, router, http.MethodPut, "/lists/sprint/items/1", createValidRequestBody("def"))
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "def", Version: 2}, item)
+
+	item, _, err = itemRequest(t, router, http.MethodPost, "/lists/sprint/items/1/complete", nil)
+	require.Nil(t, err)
+	assert.True(t, item.Done)
+
+	item, _, err = itemRequest(t, router, http.MethodPost, "/lists/sprint/items/1/tags", bytes.NewBufferString(`{"tags":["backend"]}`))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"backend"}, item.Tags)
+
+	item, _, err = itemRequest(t, router, http.MethodGet, "/lists/sprint/items/1", nil)
+	require.Nil(t, err)
+	assert.Equal(t, "def", item.Item)
+
+	items, _, err := getItems(t, router, "/lists/sprint/items?status=done")
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+
+	resp, _ := getText(t, router, "/lists/sprint")
+	assert.Equal(t, "TO-DO LIST\n----------\n1. [x] def\n", resp)
+
+	resp, _ = getText(t, router, "/lists/sprint/count")
+	assert.Equal(t, `{"count":1}`, resp)
+
+	// the default list and its flat routes are unaffected
+	items, _ = readItems(t, router)
+	assert.Equal(t, []utils.ItemAndID{}, items)
+
+	createItemValidBody(t, router, "123")
+	items, _, err = getItems(t, router, "/lists/default/items")
+	require.Nil(t, err)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "123", Version: 1}}, items)
+
+	item, _, err = itemRequest(t, router, http.MethodDelete, "/lists/sprint/items/1", nil)
+	require.Nil(t, err)
+	assert.Equal(t, 1, item.ID)
+
+	store, err := lists.Get("sprint")
+	require.Nil(t, err)
+	assert.Equal(t, 0, store.Count())
+
+	_, code, err = itemRequest(t, router, http.MethodGet, "/lists/personal/items/1", nil)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+}
+
+func TestDeleteList(t *testing.T) {
+	router, lists := setupLists()
+
+	lists.Create("sprint")
+	store, _ := lists.Get("sprint")
+	store.CreateItem("abc")
+
+	list, code, err := listRequest(t, router, http.MethodDelete, "/lists/sprint", nil)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ListInfo{Name: "sprint", Count: 1}, list)
+
+	_, code, err = listRequest(t, router, http.MethodDelete, "/lists/sprint", nil)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+
+	_, code, err = listRequest(t, router, http.MethodDelete, "/lists/default", nil)
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Equal(t, 409, code)
+
+	// a list created with the same name starts out empty
+	lists.Create("sprint")
+	store, _ = lists.Get("sprint")
+	assert.Equal(t, 0, store.Count())
+}
+
+func TestReadLists(t *testing.T) {
+	router, lists := setupLists()
+
+	lists.Create("sprint")
+	lists.Create("personal")
+	lists.Default().CreateItem("abc")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/lists", nil)
+	router.ServeHTTP(w, req)
+
+	var resp []utils.ListInfo
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []utils.ListInfo{{Name: "default", Count: 1}, {Name: "personal"}, {Name: "sprint"}}, resp)
+	assert.Equal(t, 200, w.Code)
+}
+
+func listRequest(t *testing.T, router *httprouter.Router, method string, path string, body io.Reader) (utils.ListInfo, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, body)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ListInfo{}, code, problemOf(b)
+	}
+
+	var resp utils.ListInfo
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
@@ -0,0 +1,84 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testStores = []struct {
+	name      string
+	ext       string
+	openStore func(path string) (utils.Store, error)
+}{
+	{
+		name: "memory",
+	},
+	{
+		name: "json",
+		ext:  ".json",
+		openStore: func(path string) (utils.Store, error) {
+			return utils.NewFileStore(path)
+		},
+	},
+	{
+		name: "wal",
+		openStore: func(path string) (utils.Store, error) {
+			return utils.NewWALStore(path, 2)
+		},
+	},
+	{
+		name: "sqlite",
+		ext:  ".db",
+		openStore: func(path string) (utils.Store, error) {
+			return utils.NewSQLStore(path)
+		},
+	},
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(runForEachStore(m))
+}
+
+func runForEachStore(m *testing.M) int {
+	root, err := os.MkdirTemp("", "todo-test-")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer os.RemoveAll(root)
+
+	for _, testStore := range testStores {
+		testStore := testStore
+		if testStore.openStore != nil {
+			newLists = func() (*utils.Lists, error) {
+				dir, err := os.MkdirTemp(root, testStore.name+"-")
+				if err != nil {
+					return nil, err
+				}
+
+				path := filepath.Join(dir, "default"+testStore.ext)
+				defaultList, err := testStore.openStore(path)
+				if err != nil {
+					return nil, err
+				}
+
+				return utils.NewLists(defaultList, utils.DirLists{
+					Dir:       filepath.Join(dir, "lists"),
+					Ext:       testStore.ext,
+					OpenStore: testStore.openStore,
+				})
+			}
+		}
+
+		fmt.Printf("running tests against the %v store\n", testStore.name)
+		code := m.Run()
+		if code != 0 {
+			return code
+		}
+	}
+
+	return 0
+}
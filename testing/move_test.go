@@ -0,0 +1,136 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestMoveItem(t *testing.T) {
+	testTable := []struct {
+		name             string
+		id               int
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedIDs      []int
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "before",
+			id:               3,
+			body:             `{"before":1}`,
+			expectedResponse: utils.ItemAndID{ID: 3, Item: "123", Version: 1},
+			expectedIDs:      []int{3, 1, 2},
+			expectedCode:     200,
+		},
+		{
+			name:             "after",
+			id:               1,
+			body:             `{"after":2}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1},
+			expectedIDs:      []int{2, 1, 3},
+			expectedCode:     200,
+		},
+		{
+			name:             "position",
+			id:               1,
+			body:             `{"position":3}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1},
+			expectedIDs:      []int{2, 3, 1},
+			expectedCode:     200,
+		},
+		{
+			name:          "position out of range",
+			id:            1,
+			body:          `{"position":0}`,
+			expectedIDs:   []int{1, 2, 3},
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "nonexistent neighbour",
+			id:            1,
+			body:          `{"before":4}`,
+			expectedIDs:   []int{1, 2, 3},
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "no target",
+			id:            1,
+			body:          `{}`,
+			expectedIDs:   []int{1, 2, 3},
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "two targets",
+			id:            1,
+			body:          `{"before":2,"position":1}`,
+			expectedIDs:   []int{1, 2, 3},
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def")
+			itemList.CreateItem("123")
+
+			item, code, err := itemRequest(t, router, http.MethodPost, fmt.Sprintf("/items/%v/move", testCase.id), bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+
+			ids := []int{}
+			for _, item := range itemList.ReadAll() {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestMoveItem_Print(t *testing.T) {
+	router, itemList := setup()
+	itemList.CreateItem("release")
+	itemList.CreateItem("tag", utils.WithParent(1))
+	itemList.CreateItem("publish", utils.WithParent(1))
+	itemList.CreateItem("unrelated")
+
+	_, _, err := itemRequest(t, router, http.MethodPost, "/items/3/move", bytes.NewBufferString(`{"before":2}`))
+	require.Nil(t, err)
+	_, _, err = itemRequest(t, router, http.MethodPost, "/items/4/move", bytes.NewBufferString(`{"position":1}`))
+	require.Nil(t, err)
+
+	resp, _ := printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n"+
+		"4. [ ] unrelated\n"+
+		"1. [ ] release (0/2 done)\n"+
+		"  3. [ ] publish\n"+
+		"  2. [ ] tag\n", resp)
+}
+
+func TestMoveItem_NamedList(t *testing.T) {
+	router, lists := setupLists()
+
+	lists.Create("sprint")
+	store, _ := lists.Get("sprint")
+	store.CreateItem("abc")
+	store.CreateItem("def")
+
+	item, code, err := itemRequest(t, router, http.MethodPost, "/lists/sprint/items/2/move", bytes.NewBufferString(`{"before":1}`))
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 2, Item: "def", Version: 1}, item)
+	assert.Equal(t, "def", store.ReadAll()[0].Item)
+}
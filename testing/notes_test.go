@@ -0,0 +1,124 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const notes = "Steps:\n\n1. Check the `expiry` date\n2. Run **renew**\n"
+
+func TestCreateItem_Notes(t *testing.T) {
+	router, _ := setup()
+
+	itemNotes := notes
+	item, code, err := createItem(t, router, createRequestBody(backend.RequestBody{Item: "renew certs", Notes: &itemNotes}))
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "renew certs", Version: 1, Notes: notes}, item)
+
+	item, _, err = readItem(t, router, 1)
+	require.Nil(t, err)
+	assert.Equal(t, notes, item.Notes)
+}
+
+func TestReadAll_Notes(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedNotes []string
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:          "left out by default",
+			query:         "",
+			expectedNotes: []string{"", ""},
+			expectedCode:  200,
+		},
+		{
+			name:          "asked for",
+			query:         "fields=notes",
+			expectedNotes: []string{notes, ""},
+			expectedCode:  200,
+		},
+		{
+			name:          "unknown field",
+			query:         "fields=notes,secrets",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("renew certs", utils.WithNotes(notes))
+			itemList.CreateItem("rotate on-call")
+
+			items, code, err := readItemsWithQuery(t, router, testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+			if testCase.expectedError == nil {
+				itemNotes := []string{}
+				for _, item := range items {
+					itemNotes = append(itemNotes, item.Notes)
+				}
+				assert.Equal(t, testCase.expectedNotes, itemNotes)
+			}
+		})
+	}
+}
+
+func TestUpdateItem_Notes(t *testing.T) {
+	testTable := []struct {
+		name             string
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "notes without the title",
+			body:             `{"notes":"new notes"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "renew certs", Version: 2, Notes: "new notes"},
+			expectedCode:     200,
+		},
+		{
+			name:             "title without the notes",
+			body:             `{"item":"renew the certs"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "renew the certs", Version: 2, Notes: notes},
+			expectedCode:     200,
+		},
+		{
+			name:             "clearing the notes",
+			body:             `{"notes":""}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "renew certs", Version: 2},
+			expectedCode:     200,
+		},
+		{
+			name:          "nothing to update",
+			body:          `{}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("renew certs", utils.WithNotes(notes))
+
+			item, code, err := updateItem(t, router, 1, bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadAll_Pages(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedLink  string
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "first page",
+			query:        "limit=2",
+			expectedIDs:  []int{1, 2},
+			expectedLink: `</read?limit=2>; rel="first", </read?cursor=eyJhIjoyLCJuIjozLCJvIjoyfQ&limit=2>; rel="next"`,
+			expectedCode: 200,
+		},
+		{
+			name:         "offset",
+			query:        "limit=2&offset=2",
+			expectedIDs:  []int{3, 4},
+			expectedLink: `</read?limit=2>; rel="first", </read?limit=2&offset=4>; rel="next", </read?limit=2&offset=0>; rel="prev"`,
+			expectedCode: 200,
+		},
+		{
+			name:         "last page",
+			query:        "limit=2&offset=4&status=open",
+			expectedIDs:  []int{5},
+			expectedLink: `</read?limit=2&status=open>; rel="first", </read?limit=2&offset=2&status=open>; rel="prev"`,
+			expectedCode: 200,
+		},
+		{
+			name:         "default limit",
+			query:        "offset=0",
+			expectedIDs:  []int{1, 2, 3, 4, 5},
+			expectedLink: `</read>; rel="first"`,
+			expectedCode: 200,
+		},
+		{
+			name:          "limit not a number",
+			query:         "limit=all",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "invalid cursor",
+			query:         "cursor=abc",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			// {"a":0,"o":-5}
+			name:          "tampered cursor",
+			query:         "cursor=eyJhIjowLCJvIjotNX0",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			for _, item := range []string{"a", "b", "c", "d", "e"} {
+				itemList.CreateItem(item)
+			}
+
+			page, link, code, err := readPage(t, router, "/read?"+testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+			if testCase.expectedError == nil {
+				ids := []int{}
+				for _, item := range page.Items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+				assert.Equal(t, testCase.expectedLink, link)
+			}
+		})
+	}
+}
+
+func TestReadAll_CursorAcrossChanges(t *testing.T) {
+	router, itemList := setup()
+	for _, item := range []string{"a", "b", "c", "d", "e"} {
+		itemList.CreateItem(item)
+	}
+
+	first, _, code, err := readPage(t, router, "/read?limit=2")
+	require.Nil(t, err)
+	require.Equal(t, 200, code)
+
+	// neither a new first item nor the deleted last item of the page shift
+	// the next one
+	itemList.CreateItem("f")
+	_, err = itemList.MoveItem(6, utils.MoveToPosition(1))
+	require.Nil(t, err)
+	_, err = itemList.DeleteItem(2)
+	require.Nil(t, err)
+
+	second, _, code, err := readPage(t, router, "/read?limit=2&cursor="+first.NextCursor)
+	require.Nil(t, err)
+	require.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 3, Item: "c", Version: 1}, {ID: 4, Item: "d", Version: 1}}, second.Items)
+
+	third, link, code, err := readPage(t, router, "/read?limit=2&cursor="+second.NextCursor)
+	require.Nil(t, err)
+	require.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 5, Item: "e", Version: 1}}, third.Items)
+	assert.Empty(t, third.NextCursor)
+	assert.Equal(t, `</read?limit=2>; rel="first"`, link)
+}
+
+func TestReadAll_NamedListPages(t *testing.T) {
+	router, lists := setupLists()
+	_, err := lists.Create("sprint")
+	require.Nil(t, err)
+	store, err := lists.Get("sprint")
+	require.Nil(t, err)
+	store.CreateItem("a")
+	store.CreateItem("b")
+
+	page, link, code, err := readPage(t, router, "/lists/sprint/items?limit=1")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "a", Version: 1}}, page.Items)
+	assert.Equal(t, `</lists/sprint/items?limit=1>; rel="first", </lists/sprint/items?cursor=`+page.NextCursor+`&limit=1>; rel="next"`, link)
+}
+
+func readPage(t *testing.T, router *httprouter.Router, path string) (utils.ItemPage, string, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ItemPage{}, "", code, problemOf(b)
+	}
+
+	var resp utils.ItemPage
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, w.Header().Get("Link"), code, nil
+}
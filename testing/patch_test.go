@@ -0,0 +1,200 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPatchItem(t *testing.T) {
+	due := time.Date(2050, 1, 2, 9, 0, 0, 0, time.UTC)
+	newDue := time.Date(2050, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name             string
+		patch            string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "empty patch",
+			patch:            `{}`,
+			expectedResponse: utils.ItemAndID{ID: 2, Item: "def", Version: 2, Notes: "notes", Due: &due, Priority: utils.PriorityHigh, Tags: []string{"oncall"}, ParentID: 1},
+			expectedCode:     200,
+		},
+		{
+			name:             "single field",
+			patch:            `{"priority":"low"}`,
+			expectedResponse: utils.ItemAndID{ID: 2, Item: "def", Version: 3, Notes: "notes", Due: &due, Priority: utils.PriorityLow, Tags: []string{"oncall"}, ParentID: 1},
+			expectedCode:     200,
+		},
+		{
+			name:             "several fields",
+			patch:            `{"item":"xyz","due":"2050-03-04T09:00:00Z","tags":["Backend","oncall"],"recurrence":"weekly"}`,
+			expectedResponse: utils.ItemAndID{ID: 2, Item: "xyz", Version: 3, Notes: "notes", Due: &newDue, Priority: utils.PriorityHigh, Tags: []string{"backend", "oncall"}, ParentID: 1, Recurrence: "weekly", RecurrenceStart: &newDue},
+			expectedCode:     200,
+		},
+		{
+			name:             "null clears fields",
+			patch:            `{"notes":null,"due":null,"priority":null,"tags":null,"parent_id":null,"recurrence":null}`,
+			expectedResponse: utils.ItemAndID{ID: 2, Item: "def", Version: 3},
+			expectedCode:     200,
+		},
+		{
+			name:          "invalid due",
+			patch:         `{"due":"tomorrow"}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "invalid priority",
+			patch:         `{"priority":"asap"}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "invalid tag",
+			patch:         `{"tags":[" "]}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "wrong type",
+			patch:         `{"parent_id":"1"}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "nonexistent parent",
+			patch:         `{"parent_id":5}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "clearing the item",
+			patch:         `{"item":null}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "empty item",
+			patch:         `{"item":""}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "unknown fields",
+			patch:         `{"id":3,"done":true}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "not an object",
+			patch:         `["item"]`,
+			expectedError: backend.ErrMalformedRequest,
+			expectedCode:  400,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def", utils.WithNotes("notes"), utils.WithDue(due), utils.WithPriority(utils.PriorityHigh), utils.WithParent(1))
+			itemList.AddTags(2, "oncall")
+
+			item, code, err := patchItem(t, router, "/items/2", "application/merge-patch+json", bytes.NewBufferString(testCase.patch))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+
+			// failed patches don't change anything
+			if testCase.expectedError != nil {
+				item, err := itemList.ReadItem(2)
+				require.Nil(t, err)
+				assert.Equal(t, "def", item.Item)
+				assert.Equal(t, utils.PriorityHigh, item.Priority)
+			}
+		})
+	}
+}
+
+func TestPatchItem_ContentType(t *testing.T) {
+	testTable := []struct {
+		name          string
+		contentType   string
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "merge patch",
+			contentType:  "application/merge-patch+json",
+			expectedCode: 200,
+		},
+		{
+			name:         "merge patch with charset",
+			contentType:  "application/merge-patch+json; charset=utf-8",
+			expectedCode: 200,
+		},
+		{
+			name:          "json",
+			contentType:   "application/json",
+			expectedError: backend.ErrUnsupportedMediaType,
+			expectedCode:  415,
+		},
+		{
+			name:          "none",
+			contentType:   "",
+			expectedError: backend.ErrUnsupportedMediaType,
+			expectedCode:  415,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+
+			_, code, err := patchItem(t, router, "/items/1", testCase.contentType, bytes.NewBufferString(`{"item":"def"}`))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func patchItem(t *testing.T, router *httprouter.Router, path string, contentType string, body io.Reader) (utils.ItemAndID, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
+	var resp utils.ItemAndID
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
@@ -0,0 +1,161 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateItem_Priority(t *testing.T) {
+	testTable := []struct {
+		name             string
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "no priority",
+			body:             `{"item":"abc"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1},
+			expectedCode:     200,
+		},
+		{
+			name:             "priority name",
+			body:             `{"item":"abc","priority":"High"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1, Priority: utils.PriorityHigh},
+			expectedCode:     200,
+		},
+		{
+			name:             "priority number",
+			body:             `{"item":"abc","priority":4}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 1, Priority: utils.PriorityUrgent},
+			expectedCode:     200,
+		},
+		{
+			name:          "invalid priority",
+			body:          `{"item":"abc","priority":5}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, _ := setup()
+
+			response, code, err := createItem(t, router, bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, response)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestUpdateItem_Priority(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc", utils.WithPriority(utils.PriorityLow))
+
+	item, _, err := updateItemValidBody(t, router, 1, "def")
+	require.Nil(t, err)
+	assert.Equal(t, utils.PriorityLow, item.Priority)
+
+	item, _, err = updateItem(t, router, 1, bytes.NewBufferString(`{"item":"def","priority":"medium"}`))
+	require.Nil(t, err)
+	assert.Equal(t, utils.PriorityMedium, item.Priority)
+
+	item, _, err = updateItem(t, router, 1, bytes.NewBufferString(`{"item":"def","priority":"none"}`))
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "def", Version: 4}, item)
+}
+
+func TestSortByPriority(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("none")
+	itemList.CreateItem("high", utils.WithPriority(utils.PriorityHigh))
+	itemList.CreateItem("low", utils.WithPriority(utils.PriorityLow))
+	itemList.CreateItem("another high", utils.WithPriority(utils.PriorityHigh))
+	itemList.CreateItem("urgent", utils.WithPriority(utils.PriorityUrgent))
+
+	items, code, err := readItemsWithQuery(t, router, "sort=priority")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+
+	ids := []int{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{5, 2, 4, 3, 1}, ids)
+
+	resp, _ := getText(t, router, "/?sort=priority")
+	assert.Equal(t, "TO-DO LIST\n----------\n"+
+		"5. [ ] urgent (urgent priority)\n"+
+		"2. [ ] high (high priority)\n"+
+		"4. [ ] another high (high priority)\n"+
+		"3. [ ] low (low priority)\n"+
+		"1. [ ] none\n", resp)
+
+	_, code, err = readItemsWithQuery(t, router, "sort=colour")
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+}
+
+func TestCount_ByPriority(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("none")
+	itemList.CreateItem("high", utils.WithPriority(utils.PriorityHigh))
+	itemList.CreateItem("another high", utils.WithPriority(utils.PriorityHigh))
+	itemList.CreateItem("urgent", utils.WithPriority(utils.PriorityUrgent))
+
+	response, code := countWithQuery(t, router, "by=priority")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, backend.CountResponse{
+		Count:      4,
+		ByPriority: map[string]int{"urgent": 1, "high": 2, "medium": 0, "low": 0, "none": 1},
+	}, response)
+
+	resp, code := getText(t, router, "/count?by=colour")
+	assert.ErrorIs(t, problemOf([]byte(resp)), utils.ErrValidation)
+	assert.Equal(t, 422, code)
+}
+
+func countWithQuery(t *testing.T, router *httprouter.Router, query string) (backend.CountResponse, int) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/count?"+query, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	var response backend.CountResponse
+	require.Nil(t, json.Unmarshal(b, &response))
+
+	return response, code
+}
+
+func getText(t *testing.T, router *httprouter.Router, path string) (string, int) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	return string(b), code
+}
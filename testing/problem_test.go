@@ -0,0 +1,78 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	testTable := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "invalid id",
+			method:       http.MethodGet,
+			path:         "/read/abc",
+			expectedCode: 400,
+			expectedBody: `{"type":"/problems/invalid-id","title":"Invalid ID","status":400,"detail":"error converting id to number: strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:         "not found",
+			method:       http.MethodGet,
+			path:         "/read/9",
+			expectedCode: 404,
+			expectedBody: `{"type":"/problems/not-found","title":"Not Found","status":404,"detail":"item with id (9) does not exist"}`,
+		},
+		{
+			name:         "validation failed",
+			method:       http.MethodPost,
+			path:         "/create",
+			body:         `{"item":"abc","priority":"asap"}`,
+			expectedCode: 422,
+			expectedBody: `{"type":"/problems/validation-failed","title":"Validation Failed","status":422,"detail":"priority (asap) has to be one of low, medium, high, urgent or 1 to 4","errors":[{"field":"priority","detail":"priority (asap) has to be one of low, medium, high, urgent or 1 to 4"}]}`,
+		},
+		{
+			name:         "invalid query parameter",
+			method:       http.MethodGet,
+			path:         "/read?status=finished",
+			expectedCode: 422,
+			expectedBody: `{"type":"/problems/validation-failed","title":"Validation Failed","status":422,"detail":"status (finished) has to be one of open, done or all","errors":[{"field":"status","detail":"status (finished) has to be one of open, done or all"}]}`,
+		},
+		{
+			name:         "conflict",
+			method:       http.MethodDelete,
+			path:         "/delete/1",
+			expectedCode: 409,
+			expectedBody: `{"type":"/problems/conflict","title":"Conflict","status":409,"detail":"item with id (1) has subtasks"}`,
+		},
+		{
+			name:         "malformed body",
+			method:       http.MethodPost,
+			path:         "/create",
+			body:         `{"item":1}`,
+			expectedCode: 400,
+			expectedBody: `{"type":"/problems/malformed-request","title":"Malformed Request","status":400,"detail":"json: cannot unmarshal number into Go struct field RequestBody.item of type string"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def", utils.WithParent(1))
+
+			w := etagRequest(router, testCase.method, testCase.path, nil, testCase.body)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.expectedBody, w.Body.String())
+		})
+	}
+}
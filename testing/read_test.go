@@ -28,12 +28,12 @@ func TestPrintItems(t *testing.T) {
 		{
 			name:             "one item",
 			values:           []string{"abc"},
-			expectedResponse: "TO-DO LIST\n----------\n1. abc\n",
+			expectedResponse: "TO-DO LIST\n----------\n1. [ ] abc\n",
 		},
 		{
 			name:             "multiple items",
 			values:           []string{"abc", "def", "123"},
-			expectedResponse: "TO-DO LIST\n----------\n1. abc\n2. def\n3. 123\n",
+			expectedResponse: "TO-DO LIST\n----------\n1. [ ] abc\n2. [ ] def\n3. [ ] 123\n",
 		},
 	}
 
@@ -68,14 +68,14 @@ func TestReadItem_IDValidity(t *testing.T) {
 		{
 			name:          "invalid id below 1",
 			id:            0,
-			expectedError: fmt.Errorf("id is less than 1"),
+			expectedError: utils.ErrInvalidID,
 			expectedCode:  400,
 		},
 		{
-			name:          "invalid id above size",
+			name:          "nonexistent id",
 			id:            3,
-			expectedError: fmt.Errorf("id (3) is more than the number of items (2)"),
-			expectedCode:  400,
+			expectedError: utils.ErrNotFound,
+			expectedCode:  404,
 		},
 	}
 
@@ -87,7 +87,7 @@ func TestReadItem_IDValidity(t *testing.T) {
 			itemList.CreateItem("world")
 			_, code, err := readItem(t, router, testCase.id)
 
-			assert.Equal(t, testCase.expectedError, err)
+			assert.ErrorIs(t, err, testCase.expectedError)
 			assert.Equal(t, testCase.expectedCode, code)
 		})
 	}
@@ -107,12 +107,12 @@ func TestReadAll(t *testing.T) {
 		{
 			name:             "one item",
 			values:           []string{"abc"},
-			expectedResponse: []utils.ItemAndID{{ID: 1, Item: "abc"}},
+			expectedResponse: []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}},
 		},
 		{
 			name:             "multiple items",
 			values:           []string{"abc", "def", "123"},
-			expectedResponse: []utils.ItemAndID{{ID: 1, Item: "abc"}, {ID: 2, Item: "def"}, {ID: 3, Item: "123"}},
+			expectedResponse: []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "def", Version: 1}, {ID: 3, Item: "123", Version: 1}},
 		},
 	}
 
@@ -192,11 +192,13 @@ func readItem(t *testing.T, router *httprouter.Router, id int) (utils.ItemAndID,
 	b, err := io.ReadAll(w.Body)
 	require.Nil(t, err)
 
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
 	var resp utils.ItemAndID
 	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return utils.ItemAndID{}, code, fmt.Errorf(string(b))
-	}
+	require.Nil(t, err)
 
 	return resp, code, nil
 }
@@ -0,0 +1,117 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateItem_Recurrence(t *testing.T) {
+	due := time.Date(2050, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name             string
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "named rule",
+			body:             `{"item":"rotate on-call","due":"2050-01-31T09:00:00Z","recurrence":"Monthly"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "rotate on-call", Version: 1, Due: &due, Recurrence: "monthly", RecurrenceStart: &due},
+			expectedCode:     200,
+		},
+		{
+			name:             "cron expression",
+			body:             `{"item":"rotate on-call","recurrence":"0 9 * * 1"}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "rotate on-call", Version: 1, Recurrence: "0 9 * * 1"},
+			expectedCode:     200,
+		},
+		{
+			name:          "invalid rule",
+			body:          `{"item":"rotate on-call","recurrence":"every now and then"}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, _ := setup()
+
+			item, code, err := createItem(t, router, bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestCompleteItem_Recurrence(t *testing.T) {
+	router, _ := setup()
+
+	_, _, err := createItem(t, router, bytes.NewBufferString(`{"item":"renew certs","due":"2050-01-31T09:00:00Z","recurrence":"monthly","priority":"high"}`))
+	require.Nil(t, err)
+
+	february := time.Date(2050, 2, 28, 9, 0, 0, 0, time.UTC)
+	response, code, err := readItemResponse(t, router, "/read/1")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "monthly", response.Recurrence)
+	assert.Equal(t, &february, response.NextOccurrence)
+
+	_, _, err = completeItem(t, router, 1)
+	require.Nil(t, err)
+
+	response, _, err = readItemResponse(t, router, "/read/2")
+	require.Nil(t, err)
+	assert.Equal(t, "renew certs", response.Item)
+	assert.Equal(t, &february, response.Due)
+	assert.Equal(t, utils.PriorityHigh, response.Priority)
+	assert.False(t, response.Done)
+
+	// the series keeps its day of the month after February
+	march := time.Date(2050, 3, 31, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, &march, response.NextOccurrence)
+
+	// stopping the recurrence
+	item, _, err := updateItem(t, router, 2, bytes.NewBufferString(`{"item":"renew certs","recurrence":""}`))
+	require.Nil(t, err)
+	assert.Equal(t, "", item.Recurrence)
+
+	response, _, err = readItemResponse(t, router, "/read/2")
+	require.Nil(t, err)
+	assert.Nil(t, response.NextOccurrence)
+}
+
+func readItemResponse(t *testing.T, router *httprouter.Router, path string) (backend.ReadItemResponse, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return backend.ReadItemResponse{}, code, problemOf(b)
+	}
+
+	var resp backend.ReadItemResponse
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
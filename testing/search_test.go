@@ -0,0 +1,179 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedError error
+		expectedCode  int
+	}{
+		{
+			name:         "ranked by relevance",
+			query:        "q=" + url.QueryEscape("Rotating"),
+			expectedIDs:  []int{1, 3},
+			expectedCode: 200,
+		},
+		{
+			name:         "every word has to match",
+			query:        "q=" + url.QueryEscape("cert rotation"),
+			expectedIDs:  []int{1},
+			expectedCode: 200,
+		},
+		{
+			name:         "prefix",
+			query:        "q=" + url.QueryEscape("renew cer"),
+			expectedIDs:  []int{2},
+			expectedCode: 200,
+		},
+		{
+			name:         "limit and offset",
+			query:        "limit=1&offset=1&q=" + url.QueryEscape("rotation"),
+			expectedIDs:  []int{3},
+			expectedCode: 200,
+		},
+		{
+			name:         "offset past the results",
+			query:        "offset=5&q=" + url.QueryEscape("rotation"),
+			expectedIDs:  []int{},
+			expectedCode: 200,
+		},
+		{
+			name:          "without words",
+			query:         "q=" + url.QueryEscape("the"),
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "without query",
+			query:         "",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "cursor",
+			query:         "cursor=abc&q=cert",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "negative limit",
+			query:         "limit=-1&q=cert",
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("Rotate the TLS certs", utils.WithNotes("Follow the cert rotation runbook."))
+			itemList.CreateItem("Renew the certificate of the domain")
+			itemList.CreateItem("Plan the on-call rotation")
+
+			results, code, err := searchItems(t, router, "/search?"+testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedCode, code)
+			if testCase.expectedError == nil {
+				ids := []int{}
+				for _, result := range results {
+					ids = append(ids, result.Item.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestSearch_Highlight(t *testing.T) {
+	router, itemList := setup()
+	itemList.CreateItem("Rotate the TLS certs", utils.WithNotes("Follow the cert rotation runbook."))
+
+	results, _, err := searchItems(t, router, "/search?q=rotation")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, utils.Highlight{Item: "**Rotate** the TLS certs", Notes: "Follow the cert **rotation** runbook."}, results[0].Highlight)
+	assert.Equal(t, "", results[0].Item.Notes)
+	assert.Greater(t, results[0].Score, 0.0)
+
+	results, _, err = searchItems(t, router, "/search?q=rotation&fields=notes")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Follow the cert rotation runbook.", results[0].Item.Notes)
+}
+
+func TestSearch_FollowsChanges(t *testing.T) {
+	router, _ := setupLists()
+
+	_, _, err := listRequest(t, router, http.MethodPost, "/lists", bytes.NewBufferString(`{"name":"ops"}`))
+	require.Nil(t, err)
+
+	_, _, err = itemRequest(t, router, http.MethodPost, "/lists/ops/items", createValidRequestBody("Rotate the certs"))
+	require.Nil(t, err)
+	_, _, err = itemRequest(t, router, http.MethodPost, "/lists/ops/items", createValidRequestBody("Buy milk"))
+	require.Nil(t, err)
+
+	_, _, err = itemRequest(t, router, http.MethodPut, "/lists/ops/items/2", createValidRequestBody("Renew the certs"))
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, searchIDs(t, router, "/lists/ops/search?q=certs"))
+	assert.Equal(t, []int{}, searchIDs(t, router, "/lists/ops/search?q=milk"))
+
+	_, _, err = itemRequest(t, router, http.MethodDelete, "/lists/ops/items/1", nil)
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, searchIDs(t, router, "/lists/ops/search?q=certs"))
+
+	w := etagRequest(router, http.MethodPost, "/lists/ops/undo", nil, "")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, []int{1, 2}, searchIDs(t, router, "/lists/ops/search?q=certs"))
+
+	// the default list has an index of its own
+	assert.Equal(t, []int{}, searchIDs(t, router, "/search?q=certs"))
+}
+
+func searchIDs(t *testing.T, router *httprouter.Router, path string) []int {
+	results, _, err := searchItems(t, router, path)
+	require.Nil(t, err)
+
+	ids := []int{}
+	for _, result := range results {
+		ids = append(ids, result.Item.ID)
+	}
+
+	return ids
+}
+
+func searchItems(t *testing.T, router *httprouter.Router, path string) ([]utils.SearchResult, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return nil, code, problemOf(b)
+	}
+
+	var resp []utils.SearchResult
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
@@ -0,0 +1,176 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateItem_Parent(t *testing.T) {
+	testTable := []struct {
+		name             string
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "existing parent",
+			body:             `{"item":"def","parent_id":1}`,
+			expectedResponse: utils.ItemAndID{ID: 2, Item: "def", Version: 1, ParentID: 1},
+			expectedCode:     200,
+		},
+		{
+			name:          "nonexistent parent",
+			body:          `{"item":"def","parent_id":5}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+
+			item, code, err := createItem(t, router, bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestUpdateItem_Parent(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("release")
+	itemList.CreateItem("tag", utils.WithParent(1))
+	itemList.CreateItem("publish")
+
+	item, _, err := updateItem(t, router, 3, bytes.NewBufferString(`{"item":"publish","parent_id":2}`))
+	require.Nil(t, err)
+	assert.Equal(t, 2, item.ParentID)
+
+	_, code, err := updateItem(t, router, 1, bytes.NewBufferString(`{"item":"release","parent_id":3}`))
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+
+	item, _, err = updateItem(t, router, 3, bytes.NewBufferString(`{"item":"publish","parent_id":0}`))
+	require.Nil(t, err)
+	assert.Equal(t, 0, item.ParentID)
+}
+
+func TestReadItem_Children(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("release")
+	itemList.CreateItem("tag", utils.WithParent(1))
+	itemList.CreateItem("publish", utils.WithParent(1))
+	itemList.CreateItem("upload", utils.WithParent(3))
+	itemList.CreateItem("announce", utils.WithParent(3))
+	itemList.CreateItem("unrelated")
+	itemList.CompleteItem(2)
+	itemList.CompleteItem(4)
+
+	tree, code, err := readTree(t, router, "/read/1?children=true")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+
+	// completion times are set by the server, so leave them out of the comparison
+	tree.Children[0].CompletedAt = nil
+	tree.Children[1].Children[0].CompletedAt = nil
+	assert.Equal(t, utils.ItemTree{
+		ItemAndID: utils.ItemAndID{ID: 1, Item: "release", Version: 1},
+		Progress:  &utils.Progress{Done: 2, Total: 4},
+		Children: []utils.ItemTree{
+			{ItemAndID: utils.ItemAndID{ID: 2, Item: "tag", Version: 2, Done: true, ParentID: 1}},
+			{
+				ItemAndID: utils.ItemAndID{ID: 3, Item: "publish", Version: 1, ParentID: 1},
+				Progress:  &utils.Progress{Done: 1, Total: 2},
+				Children: []utils.ItemTree{
+					{ItemAndID: utils.ItemAndID{ID: 4, Item: "upload", Version: 2, Done: true, ParentID: 3}},
+					{ItemAndID: utils.ItemAndID{ID: 5, Item: "announce", Version: 1, ParentID: 3}},
+				},
+			},
+		},
+	}, tree)
+
+	// without children only the progress is reported
+	tree, _, err = readTree(t, router, "/read/3")
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemTree{
+		ItemAndID: utils.ItemAndID{ID: 3, Item: "publish", Version: 1, ParentID: 1},
+		Progress:  &utils.Progress{Done: 1, Total: 2},
+	}, tree)
+
+	_, code, err = readTree(t, router, "/read/1?children=maybe")
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
+
+	resp, _ := printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n"+
+		"1. [ ] release (2/4 done)\n"+
+		"  2. [x] tag\n"+
+		"  3. [ ] publish (1/2 done)\n"+
+		"    4. [x] upload\n"+
+		"    5. [ ] announce\n"+
+		"6. [ ] unrelated\n", resp)
+}
+
+func TestDeleteItem_Children(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("release")
+	itemList.CreateItem("tag", utils.WithParent(1))
+	itemList.CreateItem("publish", utils.WithParent(2))
+	itemList.CreateItem("unrelated")
+
+	_, code, err := deleteItem(t, router, 1)
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, 4, itemList.Count())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/delete/1?cascade=true", nil)
+	router.ServeHTTP(w, req)
+
+	var deleted []utils.ItemAndID
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []utils.ItemAndID{
+		{ID: 1, Item: "release", Version: 1},
+		{ID: 2, Item: "tag", Version: 1, ParentID: 1},
+		{ID: 3, Item: "publish", Version: 1, ParentID: 2},
+	}, deleted)
+	assert.Equal(t, []utils.ItemAndID{{ID: 4, Item: "unrelated", Version: 1}}, itemList.ReadAll())
+}
+
+func readTree(t *testing.T, router *httprouter.Router, path string) (utils.ItemTree, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ItemTree{}, code, problemOf(b)
+	}
+
+	var resp utils.ItemTree
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
@@ -0,0 +1,218 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAddTags(t *testing.T) {
+	testTable := []struct {
+		name             string
+		id               int
+		body             string
+		expectedResponse utils.ItemAndID
+		expectedError    error
+		expectedCode     int
+	}{
+		{
+			name:             "new tags are normalized",
+			id:               1,
+			body:             `{"tags":["Oncall ", "  release   notes"]}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 3, Tags: []string{"backend", "oncall", "release notes"}},
+			expectedCode:     200,
+		},
+		{
+			name:             "existing tag",
+			id:               1,
+			body:             `{"tags":["BACKEND"]}`,
+			expectedResponse: utils.ItemAndID{ID: 1, Item: "abc", Version: 2, Tags: []string{"backend"}},
+			expectedCode:     200,
+		},
+		{
+			name:          "empty tag",
+			id:            1,
+			body:          `{"tags":["  "]}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "no tags",
+			id:            1,
+			body:          `{}`,
+			expectedError: utils.ErrValidation,
+			expectedCode:  422,
+		},
+		{
+			name:          "nonexistent id",
+			id:            2,
+			body:          `{"tags":["backend"]}`,
+			expectedError: utils.ErrNotFound,
+			expectedCode:  404,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("abc")
+			itemList.AddTags(1, "backend")
+
+			item, code, err := itemRequest(t, router, http.MethodPost, fmt.Sprintf("/items/%v/tags", testCase.id), bytes.NewBufferString(testCase.body))
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Equal(t, testCase.expectedResponse, item)
+			assert.Equal(t, testCase.expectedCode, code)
+		})
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc")
+	itemList.AddTags(1, "backend", "oncall")
+
+	item, code, err := itemRequest(t, router, http.MethodDelete, "/items/1/tags/"+url.PathEscape("OnCall "), nil)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc", Version: 3, Tags: []string{"backend"}}, item)
+
+	_, code, err = itemRequest(t, router, http.MethodDelete, "/items/1/tags/oncall", nil)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+
+	item, _, err = itemRequest(t, router, http.MethodDelete, "/items/1/tags/backend", nil)
+	require.Nil(t, err)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc", Version: 4}, item)
+}
+
+func TestReadAll_Tags(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedError error
+	}{
+		{
+			name:        "single tag",
+			query:       "tag=backend",
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "tags are normalized",
+			query:       "tag=%20OnCall",
+			expectedIDs: []int{2, 3},
+		},
+		{
+			name:        "and by default",
+			query:       "tag=backend&tag=oncall",
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "or",
+			query:       "tag=backend&tag=oncall&tag_mode=or",
+			expectedIDs: []int{1, 2, 3},
+		},
+		{
+			name:        "unused tag",
+			query:       "tag=frontend",
+			expectedIDs: []int{},
+		},
+		{
+			name:          "invalid mode",
+			query:         "tag=backend&tag_mode=xor",
+			expectedError: utils.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def")
+			itemList.CreateItem("123")
+			itemList.CreateItem("456")
+			itemList.AddTags(1, "backend")
+			itemList.AddTags(2, "backend", "oncall")
+			itemList.AddTags(3, "oncall")
+
+			items, _, err := readItemsWithQuery(t, router, testCase.query)
+
+			assert.ErrorIs(t, err, testCase.expectedError)
+			if testCase.expectedError == nil {
+				ids := []int{}
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestListTags(t *testing.T) {
+	router, itemList := setup()
+
+	tags, code := listTags(t, router)
+	assert.Equal(t, []utils.TagCount{}, tags)
+	assert.Equal(t, 200, code)
+
+	itemList.CreateItem("abc")
+	itemList.CreateItem("def")
+	itemList.AddTags(1, "oncall", "backend")
+	itemList.AddTags(2, "Backend")
+
+	tags, code = listTags(t, router)
+	assert.Equal(t, []utils.TagCount{{Tag: "backend", Count: 2}, {Tag: "oncall", Count: 1}}, tags)
+	assert.Equal(t, 200, code)
+}
+
+// itemRequest sends a request answered with a single item.
+func itemRequest(t *testing.T, router *httprouter.Router, method string, path string, body io.Reader) (utils.ItemAndID, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, body)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
+	var resp utils.ItemAndID
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
+
+func listTags(t *testing.T, router *httprouter.Router) ([]utils.TagCount, int) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tags", nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	var resp []utils.TagCount
+	require.Nil(t, json.Unmarshal(b, &resp))
+
+	return resp, code
+}
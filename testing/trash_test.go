@@ -0,0 +1,115 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	router, itemList := setup()
+
+	itemList.CreateItem("abc")
+	itemList.CreateItem("def", utils.WithNotes("notes"))
+	itemList.CreateItem("123")
+	_, _, err := deleteItem(t, router, 3)
+	require.Nil(t, err)
+	_, code := deleteAll(t, router)
+	require.Equal(t, 200, code)
+
+	trash, code, err := readTrash(t, router, "/trash")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []utils.ItemAndID{
+		{ID: 3, Item: "123", Version: 1},
+		{ID: 1, Item: "abc", Version: 1},
+		{ID: 2, Item: "def", Version: 1},
+	}, trashedItems(trash))
+	for _, item := range trash {
+		assert.WithinDuration(t, time.Now(), item.DeletedAt, time.Minute)
+	}
+
+	trash, _, err = readTrash(t, router, "/trash?fields=notes")
+	require.Nil(t, err)
+	assert.Equal(t, "notes", trash[2].Notes)
+
+	// restored items keep their id and go to the end of the list
+	item, code, err := postItemAction(t, router, "/trash/2/restore")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 2, Item: "def", Version: 2, Notes: "notes"}, item)
+
+	_, _, err = postItemAction(t, router, "/trash/3/restore")
+	require.Nil(t, err)
+
+	assert.Equal(t, []utils.ItemAndID{{ID: 2, Item: "def", Version: 2, Notes: "notes"}, {ID: 3, Item: "123", Version: 2}}, itemList.ReadAll())
+
+	_, code, err = postItemAction(t, router, "/trash/2/restore")
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+	assert.Equal(t, 404, code)
+
+	trash, _, err = readTrash(t, router, "/trash")
+	require.Nil(t, err)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}}, trashedItems(trash))
+}
+
+func TestTrash_NamedList(t *testing.T) {
+	router, lists := setupLists()
+
+	lists.Create("sprint")
+	store, _ := lists.Get("sprint")
+	store.CreateItem("abc")
+	store.DeleteItem(1)
+
+	trash, _, err := readTrash(t, router, "/lists/sprint/trash")
+	require.Nil(t, err)
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}}, trashedItems(trash))
+
+	// the default list's trash is separate
+	trash, _, err = readTrash(t, router, "/trash")
+	require.Nil(t, err)
+	assert.Equal(t, []utils.ItemAndID{}, trashedItems(trash))
+
+	item, code, err := itemRequest(t, router, http.MethodPost, "/lists/sprint/trash/1/restore", nil)
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.ItemAndID{ID: 1, Item: "abc", Version: 2}, item)
+}
+
+func readTrash(t *testing.T, router *httprouter.Router, path string) ([]utils.TrashedItem, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return nil, code, problemOf(b)
+	}
+
+	var resp []utils.TrashedItem
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
+
+// trashedItems leaves out the deletion times, which are set by the server.
+func trashedItems(trash []utils.TrashedItem) []utils.ItemAndID {
+	items := []utils.ItemAndID{}
+	for _, item := range trash {
+		items = append(items, item.ItemAndID)
+	}
+
+	return items
+}
@@ -0,0 +1,138 @@
+package testing
+
+import (
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUndoAndRedo(t *testing.T) {
+	router, itemList := setup()
+
+	_, _, err := createItem(t, router, createValidRequestBody("abc"))
+	require.Nil(t, err)
+	_, _, err = createItem(t, router, createValidRequestBody("def"))
+	require.Nil(t, err)
+	_, _, err = createItem(t, router, createValidRequestBody("123"))
+	require.Nil(t, err)
+	_, _, err = updateItemValidBody(t, router, 1, "xyz")
+	require.Nil(t, err)
+	_, _, err = deleteItem(t, router, 2)
+	require.Nil(t, err)
+	_, code := deleteAll(t, router)
+	require.Equal(t, 200, code)
+
+	// delete all puts every item back where it was
+	op, code, err := undoRequest(t, router, "/undo")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, utils.Operation{
+		Description: "delete all items",
+		Items:       []utils.ItemAndID{{ID: 1, Item: "xyz", Version: 3}, {ID: 3, Item: "123", Version: 2}},
+	}, op)
+
+	// so does delete, with the original id
+	op, _, err = undoRequest(t, router, "/undo")
+	require.Nil(t, err)
+	assert.Equal(t, utils.Operation{
+		Description: "delete item 2",
+		Items:       []utils.ItemAndID{{ID: 2, Item: "def", Version: 2}},
+	}, op)
+	assert.Equal(t, []utils.ItemAndID{
+		{ID: 1, Item: "xyz", Version: 3},
+		{ID: 2, Item: "def", Version: 2},
+		{ID: 3, Item: "123", Version: 2},
+	}, itemList.ReadAll())
+
+	op, _, err = undoRequest(t, router, "/undo")
+	require.Nil(t, err)
+	assert.Equal(t, utils.Operation{
+		Description: "update item 1",
+		Items:       []utils.ItemAndID{{ID: 1, Item: "abc", Version: 4}},
+	}, op)
+
+	op, _, err = undoRequest(t, router, "/undo")
+	require.Nil(t, err)
+	assert.Equal(t, utils.Operation{Description: "create item 3", Items: []utils.ItemAndID{}, Removed: []int{3}}, op)
+
+	op, _, err = undoRequest(t, router, "/redo")
+	require.Nil(t, err)
+	assert.Equal(t, utils.Operation{Description: "create item 3", Items: []utils.ItemAndID{{ID: 3, Item: "123", Version: 3}}}, op)
+
+	op, _, err = undoRequest(t, router, "/redo")
+	require.Nil(t, err)
+	assert.Equal(t, "update item 1", op.Description)
+
+	resp, _ := printItems(t, router)
+	assert.Equal(t, "TO-DO LIST\n----------\n1. [ ] xyz\n2. [ ] def\n3. [ ] 123\n", resp)
+
+	// a new operation discards what could have been redone
+	_, _, err = createItem(t, router, createValidRequestBody("456"))
+	require.Nil(t, err)
+	_, code, err = undoRequest(t, router, "/redo")
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Equal(t, 409, code)
+}
+
+func TestUndo_NothingToUndo(t *testing.T) {
+	router, _ := setup()
+
+	_, code, err := undoRequest(t, router, "/undo")
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Equal(t, 409, code)
+}
+
+func TestUndo_NamedList(t *testing.T) {
+	router, lists := setupLists()
+
+	lists.Create("sprint")
+	store, _ := lists.Get("sprint")
+	store.CreateItem("abc")
+	lists.Default().CreateItem("def")
+
+	op, code, err := undoRequest(t, router, "/lists/sprint/undo")
+	require.Nil(t, err)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "create item 1", op.Description)
+	assert.Equal(t, 0, store.Count())
+	assert.Equal(t, 1, lists.Default().Count())
+
+	_, _, err = undoRequest(t, router, "/lists/sprint/redo")
+	require.Nil(t, err)
+	assert.Equal(t, 1, store.Count())
+
+	// the depth applies to lists created later on too
+	lists.SetUndoDepth(0)
+	lists.Create("backlog")
+	backlog, _ := lists.Get("backlog")
+	backlog.CreateItem("123")
+	_, _, err = undoRequest(t, router, "/lists/backlog/undo")
+	assert.ErrorIs(t, err, utils.ErrConflict)
+}
+
+func undoRequest(t *testing.T, router *httprouter.Router, path string) (utils.Operation, int, error) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, nil)
+
+	router.ServeHTTP(w, req)
+	code := w.Code
+
+	b, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+
+	if code >= 400 {
+		return utils.Operation{}, code, problemOf(b)
+	}
+
+	var resp utils.Operation
+	err = json.Unmarshal(b, &resp)
+	require.Nil(t, err)
+
+	return resp, code, nil
+}
@@ -29,14 +29,14 @@ func TestUpdateItem_IDValidity(t *testing.T) {
 		{
 			name:          "invalid id below 1",
 			id:            0,
-			expectedError: fmt.Errorf("id is less than 1"),
+			expectedError: utils.ErrInvalidID,
 			expectedCode:  400,
 		},
 		{
-			name:          "invalid id above size",
+			name:          "nonexistent id",
 			id:            3,
-			expectedError: fmt.Errorf("id (3) is more than the number of items (2)"),
-			expectedCode:  400,
+			expectedError: utils.ErrNotFound,
+			expectedCode:  404,
 		},
 	}
 
@@ -48,7 +48,7 @@ func TestUpdateItem_IDValidity(t *testing.T) {
 			itemList.CreateItem("world")
 			_, code, err := updateItemValidBody(t, router, testCase.id, "abc")
 
-			assert.Equal(t, testCase.expectedError, err)
+			assert.ErrorIs(t, err, testCase.expectedError)
 			assert.Equal(t, testCase.expectedCode, code)
 		})
 	}
@@ -61,8 +61,8 @@ func TestUpdateItem_InvalidBody(t *testing.T) {
 	_, code, err := updateItemInvalidBody(t, router, 1)
 
 	require.NotNil(t, err)
-	assert.Equal(t, "\"item\" field in body was not populated", err.Error())
-	assert.Equal(t, 400, code)
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	assert.Equal(t, 422, code)
 }
 
 func updateItemValidBody(t *testing.T, router *httprouter.Router, id int, newItem string) (utils.ItemAndID, int, error) {
@@ -83,11 +83,13 @@ func updateItem(t *testing.T, router *httprouter.Router, id int, body io.Reader)
 	b, err := io.ReadAll(w.Body)
 	require.Nil(t, err)
 
+	if code >= 400 {
+		return utils.ItemAndID{}, code, problemOf(b)
+	}
+
 	var resp utils.ItemAndID
 	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return utils.ItemAndID{}, code, fmt.Errorf(string(b))
-	}
+	require.Nil(t, err)
 
 	return resp, code, nil
 }
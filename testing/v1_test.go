@@ -0,0 +1,214 @@
+package testing
+
+import (
+	"TodoApplication/backend"
+	"TodoApplication/utils"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestV1Items(t *testing.T) {
+	testTable := []struct {
+		name          string
+		method        string
+		path          string
+		headers       map[string]string
+		body          string
+		expectedCode  int
+		expectedBody  string
+		expectedError error
+	}{
+		{
+			name:         "create",
+			method:       http.MethodPost,
+			path:         "/v1/items",
+			body:         `{"item":"ghi"}`,
+			expectedCode: 201,
+			expectedBody: `{"id":4,"item":"ghi","version":1,"done":false}`,
+		},
+		{
+			name:          "create without item",
+			method:        http.MethodPost,
+			path:          "/v1/items",
+			body:          `{"priority":"high"}`,
+			expectedCode:  422,
+			expectedError: utils.ErrValidation,
+		},
+		{
+			name:          "create with invalid priority",
+			method:        http.MethodPost,
+			path:          "/v1/items",
+			body:          `{"item":"ghi","priority":"critical"}`,
+			expectedCode:  422,
+			expectedError: utils.ErrValidation,
+		},
+		{
+			name:          "create with nonexistent parent",
+			method:        http.MethodPost,
+			path:          "/v1/items",
+			body:          `{"item":"ghi","parent_id":9}`,
+			expectedCode:  422,
+			expectedError: utils.ErrValidation,
+		},
+		{
+			name:          "create with malformed body",
+			method:        http.MethodPost,
+			path:          "/v1/items",
+			body:          `{"item":`,
+			expectedCode:  400,
+			expectedError: backend.ErrMalformedRequest,
+		},
+		{
+			name:         "read",
+			method:       http.MethodGet,
+			path:         "/v1/items/2",
+			expectedCode: 200,
+			expectedBody: `{"id":2,"item":"def","version":1,"done":false,"parent_id":1}`,
+		},
+		{
+			name:          "read nonexistent",
+			method:        http.MethodGet,
+			path:          "/v1/items/9",
+			expectedCode:  404,
+			expectedError: utils.ErrNotFound,
+		},
+		{
+			name:          "read with malformed id",
+			method:        http.MethodGet,
+			path:          "/v1/items/abc",
+			expectedCode:  400,
+			expectedError: utils.ErrInvalidID,
+		},
+		{
+			name:         "update",
+			method:       http.MethodPut,
+			path:         "/v1/items/3",
+			body:         `{"item":"xyz"}`,
+			expectedCode: 200,
+			expectedBody: `{"id":3,"item":"xyz","version":2,"done":false}`,
+		},
+		{
+			name:          "update nonexistent",
+			method:        http.MethodPut,
+			path:          "/v1/items/9",
+			body:          `{"item":"xyz"}`,
+			expectedCode:  404,
+			expectedError: utils.ErrNotFound,
+		},
+		{
+			name:          "update into a cycle",
+			method:        http.MethodPut,
+			path:          "/v1/items/1",
+			body:          `{"parent_id":2}`,
+			expectedCode:  422,
+			expectedError: utils.ErrValidation,
+		},
+		{
+			name:          "update with stale version",
+			method:        http.MethodPut,
+			path:          "/v1/items/3",
+			headers:       map[string]string{"If-Match": `"2"`},
+			body:          `{"item":"xyz"}`,
+			expectedCode:  412,
+			expectedError: utils.ErrVersionMismatch,
+		},
+		{
+			name:         "patch",
+			method:       http.MethodPatch,
+			path:         "/v1/items/3",
+			headers:      map[string]string{"Content-Type": "application/merge-patch+json"},
+			body:         `{"priority":"low"}`,
+			expectedCode: 200,
+			expectedBody: `{"id":3,"item":"123","version":2,"done":false,"priority":"low"}`,
+		},
+		{
+			name:          "patch with wrong content type",
+			method:        http.MethodPatch,
+			path:          "/v1/items/3",
+			headers:       map[string]string{"Content-Type": "application/json"},
+			body:          `{"priority":"low"}`,
+			expectedCode:  415,
+			expectedError: backend.ErrUnsupportedMediaType,
+		},
+		{
+			name:          "patch nonexistent",
+			method:        http.MethodPatch,
+			path:          "/v1/items/9",
+			headers:       map[string]string{"Content-Type": "application/merge-patch+json"},
+			body:          `{"priority":"low"}`,
+			expectedCode:  404,
+			expectedError: utils.ErrNotFound,
+		},
+		{
+			name:         "delete",
+			method:       http.MethodDelete,
+			path:         "/v1/items/3",
+			expectedCode: 204,
+		},
+		{
+			name:          "delete with subtasks",
+			method:        http.MethodDelete,
+			path:          "/v1/items/1",
+			expectedCode:  409,
+			expectedError: utils.ErrConflict,
+		},
+		{
+			name:         "delete with subtasks in cascade",
+			method:       http.MethodDelete,
+			path:         "/v1/items/1?cascade=true",
+			expectedCode: 204,
+		},
+		{
+			name:          "delete nonexistent",
+			method:        http.MethodDelete,
+			path:          "/v1/items/9",
+			expectedCode:  404,
+			expectedError: utils.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			router, itemList := setup()
+			itemList.CreateItem("abc")
+			itemList.CreateItem("def", utils.WithParent(1))
+			itemList.CreateItem("123")
+
+			w := etagRequest(router, testCase.method, testCase.path, testCase.headers, testCase.body)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			if testCase.expectedError != nil {
+				assert.ErrorIs(t, problemOf(w.Body.Bytes()), testCase.expectedError)
+			} else {
+				assert.Equal(t, testCase.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestV1Items_CreateAndList(t *testing.T) {
+	router, _ := setup()
+
+	w := etagRequest(router, http.MethodPost, "/v1/items", nil, `{"item":"abc"}`)
+	require.Equal(t, 201, w.Code)
+	assert.Equal(t, "/v1/items/1", w.Header().Get("Location"))
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = etagRequest(router, http.MethodGet, w.Header().Get("Location"), nil, "")
+	assert.Equal(t, 200, w.Code)
+
+	w = etagRequest(router, http.MethodGet, "/v1/items", nil, "")
+	require.Equal(t, 200, w.Code)
+	var items []utils.ItemAndID
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	assert.Equal(t, []utils.ItemAndID{{ID: 1, Item: "abc", Version: 1}}, items)
+
+	// the legacy routes answer 200 on success but share the error statuses
+	w = etagRequest(router, http.MethodPost, "/create", nil, `{"item":"def"}`)
+	assert.Equal(t, 200, w.Code)
+	w = etagRequest(router, http.MethodGet, "/read/9", nil, "")
+	assert.Equal(t, 404, w.Code)
+}
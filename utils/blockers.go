@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AddBlocker records that the item can't be worked on before the blocker is
+// complete. Edges that would make an item (transitively) block itself are
+// rejected.
+func (il *ItemList) AddBlocker(id int, blockerID int) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	// the blocker is named in the body rather than the URL, like a parent
+	blockerIndex := indexOf(il.items, blockerID)
+	if blockerIndex < 0 {
+		return ItemAndID{}, NewFieldError(ErrValidation, "blocker_id", "blocker item with id (%v) does not exist", blockerID)
+	}
+
+	if il.blockedBy(blockerID, id) {
+		return ItemAndID{}, NewFieldError(ErrValidation, "blocker_id", "item with id (%v) can't be blocked by item with id (%v) since that would create a cycle", id, blockerID)
+	}
+
+	blocked := il.items[index]
+	blocker := il.items[blockerIndex]
+	if containsID(blocked.BlockedBy, blockerID) {
+		return blocked, nil
+	}
+
+	blocked.BlockedBy = addID(blocked.BlockedBy, blockerID)
+	blocker.Blocks = addID(blocker.Blocks, id)
+
+	err = il.commit(fmt.Sprintf("block item %v by item %v", id, blockerID), il.put(&blocked), il.put(&blocker))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return blocked, nil
+}
+
+func (il *ItemList) RemoveBlocker(id int, blockerID int) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	blocked := il.items[index]
+	if !containsID(blocked.BlockedBy, blockerID) {
+		return ItemAndID{}, NewError(ErrNotFound, "item with id (%v) is not blocked by item with id (%v)", id, blockerID)
+	}
+
+	// the blocker exists as long as the edge does, see unlinkChanges
+	blocker := il.items[indexOf(il.items, blockerID)]
+
+	blocked.BlockedBy = removeID(blocked.BlockedBy, blockerID)
+	blocker.Blocks = removeID(blocker.Blocks, id)
+
+	err = il.commit(fmt.Sprintf("unblock item %v from item %v", id, blockerID), il.put(&blocked), il.put(&blocker))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return blocked, nil
+}
+
+// Ready returns the open items whose blockers are all complete.
+func (il *ItemList) Ready() []ItemAndID {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	done := map[int]bool{}
+	for _, item := range il.items {
+		done[item.ID] = item.Done
+	}
+
+	items := []ItemAndID{}
+	for _, item := range il.items {
+		if item.Done {
+			continue
+		}
+
+		ready := true
+		for _, blockerID := range item.BlockedBy {
+			if !done[blockerID] {
+				ready = false
+				break
+			}
+		}
+
+		if ready {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// blockedBy reports whether the item is blocked by the blocker, directly or
+// through other items.
+func (il *ItemList) blockedBy(id int, blockerID int) bool {
+	visited := map[int]bool{}
+	pending := []int{id}
+
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if current == blockerID {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		index := indexOf(il.items, current)
+		if index >= 0 {
+			pending = append(pending, il.items[index].BlockedBy...)
+		}
+	}
+
+	return false
+}
+
+// unlinkChanges returns the changes that drop the edges between the deleted
+// items and the items that are left, so that no item refers to a deleted one.
+func (il *ItemList) unlinkChanges(deleted []ItemAndID) []change {
+	deletedIDs := map[int]bool{}
+	for _, item := range deleted {
+		deletedIDs[item.ID] = true
+	}
+
+	// an item can be linked to several deleted items, so collect all of its
+	// edits before turning them into changes
+	unlinked := map[int]*ItemAndID{}
+	var order []int
+	unlink := func(id int, fn func(item *ItemAndID)) {
+		if deletedIDs[id] {
+			return
+		}
+
+		item, ok := unlinked[id]
+		if !ok {
+			index := indexOf(il.items, id)
+			if index < 0 {
+				return
+			}
+
+			cpy := il.items[index]
+			item = &cpy
+			unlinked[id] = item
+			order = append(order, id)
+		}
+
+		fn(item)
+	}
+
+	for _, item := range deleted {
+		deletedID := item.ID
+		for _, blockerID := range item.BlockedBy {
+			unlink(blockerID, func(blocker *ItemAndID) {
+				blocker.Blocks = removeID(blocker.Blocks, deletedID)
+			})
+		}
+		for _, blockedID := range item.Blocks {
+			unlink(blockedID, func(blocked *ItemAndID) {
+				blocked.BlockedBy = removeID(blocked.BlockedBy, deletedID)
+			})
+		}
+	}
+
+	changes := make([]change, 0, len(order))
+	for _, id := range order {
+		changes = append(changes, il.put(unlinked[id]))
+	}
+
+	return changes
+}
+
+func containsID(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addID and removeID return new sorted slices; like tags, the slices are
+// shared between copies of an item and must not be modified in place.
+func addID(ids []int, id int) []int {
+	added := make([]int, 0, len(ids)+1)
+	added = append(added, ids...)
+	added = append(added, id)
+	sort.Ints(added)
+
+	return added
+}
+
+func removeID(ids []int, id int) []int {
+	var removed []int
+	for _, i := range ids {
+		if i != id {
+			removed = append(removed, i)
+		}
+	}
+
+	return removed
+}
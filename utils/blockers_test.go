@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBlockedBy(t *testing.T) {
+	itemList := NewItemList()
+	for _, item := range []string{"a", "b", "c", "d"} {
+		itemList.CreateItem(item)
+	}
+	// 1 <- 2 <- 3, and 4 on its own
+	itemList.AddBlocker(1, 2)
+	itemList.AddBlocker(2, 3)
+
+	testTable := []struct {
+		name      string
+		id        int
+		blockerID int
+		expected  bool
+	}{
+		{name: "itself", id: 1, blockerID: 1, expected: true},
+		{name: "direct", id: 1, blockerID: 2, expected: true},
+		{name: "transitive", id: 1, blockerID: 3, expected: true},
+		{name: "reversed", id: 3, blockerID: 1, expected: false},
+		{name: "unrelated", id: 1, blockerID: 4, expected: false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, itemList.blockedBy(testCase.id, testCase.blockerID))
+		})
+	}
+}
+
+func TestDeleteTree_Blockers(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("a")
+	itemList.CreateItem("b", WithParent(1))
+	itemList.CreateItem("c")
+	itemList.AddBlocker(1, 2)
+	itemList.AddBlocker(3, 1)
+	itemList.AddBlocker(3, 2)
+
+	_, err := itemList.DeleteTree(1)
+	require.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 3, Item: "c", Version: 4}}, itemList.ReadAll())
+}
@@ -0,0 +1,161 @@
+package utils
+
+import "time"
+
+type changeOp string
+
+const (
+	opPut      changeOp = "put"
+	opTrash    changeOp = "trash"
+	opPurge    changeOp = "purge"
+	opMove     changeOp = "move"
+	opRevision changeOp = "revision"
+	// opRemove takes an item out of the list for good, e.g. when its creation
+	// is undone
+	opRemove changeOp = "remove"
+	// opPushUndo makes Operation the most recent operation that can be
+	// undone and forgets the ones that could be redone
+	opPushUndo changeOp = "push_undo"
+	// opUndo and opRedo move the most recent operation that can be undone,
+	// or redone, to the other stack, where it is replaced by Operation
+	opUndo changeOp = "undo"
+	opRedo changeOp = "redo"
+)
+
+// change is a single modification of the list. Every mutation of an ItemList
+// is expressed as changes so that durable stores can persist them before
+// they are applied. Changes describe the resulting state rather than the
+// operation, so applying one twice has the same effect as applying it once.
+type change struct {
+	Op   changeOp   `json:"op"`
+	Item *ItemAndID `json:"item,omitempty"`
+	ID   int        `json:"id,omitempty"`
+	// DeletedAt is the time a trashed item was deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Revision  *Revision  `json:"revision,omitempty"`
+	// Before is the id of the item a move, or a put of an item that isn't in
+	// the list yet, places the item in front of; 0 places it at the end.
+	Before int `json:"before,omitempty"`
+	// Operation is the operation an undo or redo change puts on a stack,
+	// which keeps the Depth most recent ones.
+	Operation *operation `json:"operation,omitempty"`
+	Depth     int        `json:"depth,omitempty"`
+}
+
+// journal records changes before an ItemList applies them. record is called
+// with the list locked for writing; if it fails the changes are not applied.
+type journal interface {
+	record(il *ItemList, changes []change) error
+}
+
+func putChange(item ItemAndID) change {
+	return change{Op: opPut, Item: &item}
+}
+
+// trashChange moves the item from the list to the trash.
+func trashChange(item ItemAndID, deletedAt time.Time) change {
+	return change{Op: opTrash, Item: &item, DeletedAt: &deletedAt}
+}
+
+// revisionChange appends the revision to the history.
+func revisionChange(revision Revision) change {
+	return change{Op: opRevision, Revision: &revision}
+}
+
+// moveChange moves the item in front of the item with the id before.
+func moveChange(id int, before int) change {
+	return change{Op: opMove, ID: id, Before: before}
+}
+
+// purgeChange deletes the item from the trash for good, along with its
+// revisions and the operations that changed it.
+func purgeChange(id int) change {
+	return change{Op: opPurge, ID: id}
+}
+
+// applyChanges applies the changes to the state, reusing its slices, and
+// returns the new state. A put replaces the item with the same ID in place
+// or inserts it if there is none, taking it out of the trash; the trash is
+// kept in the order the items were deleted in.
+func applyChanges(s listSnapshot, changes []change) listSnapshot {
+	for _, c := range changes {
+		switch c.Op {
+		case opPut:
+			index := indexOf(s.Items, c.Item.ID)
+			if index >= 0 {
+				s.Items[index] = *c.Item
+			} else {
+				s.Items = insertItem(s.Items, *c.Item, c.Before)
+			}
+
+			if c.Item.ID >= s.NextID {
+				s.NextID = c.Item.ID + 1
+			}
+
+			s.Trash = removeTrashed(s.Trash, c.Item.ID)
+		case opTrash:
+			s.Items = removeItem(s.Items, c.Item.ID)
+			s.Trash = removeTrashed(s.Trash, c.Item.ID)
+			s.Trash = append(s.Trash, TrashedItem{ItemAndID: *c.Item, DeletedAt: *c.DeletedAt})
+		case opPurge:
+			s.Trash = removeTrashed(s.Trash, c.ID)
+			s.History = removeRevisions(s.History, c.ID)
+			s.Undo = forgetOperations(s.Undo, c.ID)
+			s.Redo = forgetOperations(s.Redo, c.ID)
+		case opMove:
+			index := indexOf(s.Items, c.ID)
+			if index >= 0 {
+				item := s.Items[index]
+				s.Items = insertItem(removeItem(s.Items, c.ID), item, c.Before)
+			}
+		case opRevision:
+			s.History = append(s.History, *c.Revision)
+		case opRemove:
+			s.Items = removeItem(s.Items, c.ID)
+		case opPushUndo:
+			s.Undo = lastOperations(append(s.Undo, *c.Operation), c.Depth)
+			s.Redo = nil
+		case opUndo:
+			s.Undo = dropLastOperation(s.Undo)
+			s.Redo = lastOperations(append(s.Redo, *c.Operation), c.Depth)
+		case opRedo:
+			s.Redo = dropLastOperation(s.Redo)
+			s.Undo = lastOperations(append(s.Undo, *c.Operation), c.Depth)
+		}
+	}
+
+	return s
+}
+
+// insertItem inserts the item in front of the item with the id before, or
+// appends it if there is no such item.
+func insertItem(items []ItemAndID, item ItemAndID, before int) []ItemAndID {
+	index := indexOf(items, before)
+	if before == 0 || index < 0 {
+		return append(items, item)
+	}
+
+	items = append(items, ItemAndID{})
+	copy(items[index+1:], items[index:])
+	items[index] = item
+
+	return items
+}
+
+func removeItem(items []ItemAndID, id int) []ItemAndID {
+	index := indexOf(items, id)
+	if index < 0 {
+		return items
+	}
+
+	return append(items[:index], items[index+1:]...)
+}
+
+func removeTrashed(trash []TrashedItem, id int) []TrashedItem {
+	index := indexOfTrashed(trash, id)
+	if index < 0 {
+		return trash
+	}
+
+	return append(trash[:index], trash[index+1:]...)
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of errors the stores return, so that callers can tell them apart
+// with errors.Is instead of matching messages.
+var (
+	// ErrNotFound means that the item, or whatever else the error names,
+	// doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidID means that an id can't belong to any item.
+	ErrInvalidID = errors.New("invalid id")
+	// ErrValidation means that a value given by the caller is invalid.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict means that the operation isn't possible in the current
+	// state of the list.
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidQuery is the kind of a SyntaxError.
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrVersionMismatch is the kind of a VersionMismatchError.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// Error is an error of one of the kinds above; its message is the detail
+// alone, errors.Is matches the kind. Field names the field or parameter
+// the error is about, if there is a single one.
+type Error struct {
+	Kind   error
+	Field  string
+	Detail string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NewError returns an error of the given kind with a formatted detail.
+func NewError(kind error, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Detail: fmt.Sprintf(format, a...)}
+}
+
+// NewFieldError returns an error of the given kind about the given field
+// with a formatted detail.
+func NewFieldError(kind error, field string, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Field: field, Detail: fmt.Sprintf(format, a...)}
+}
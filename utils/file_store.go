@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FileStore is an ItemList that is persisted to a JSON file. The file is
+// loaded when the store is created and atomically rewritten after every
+// mutation; a mutation that cannot be written is not applied.
+type FileStore struct {
+	*ItemList
+	path string
+}
+
+var _ Store = (*FileStore)(nil)
+
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{
+		ItemList: NewItemList(),
+		path:     path,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// write the empty list straight away so an unusable path is reported
+		// on startup rather than on the first mutation
+		err = fs.save(fs.snapshot())
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		s, err := decodeSnapshot(b)
+		if err != nil {
+			return nil, fmt.Errorf("error loading %v: %v", path, err)
+		}
+
+		err = fs.restore(s)
+		if err != nil {
+			return nil, fmt.Errorf("error loading %v: %v", path, err)
+		}
+	}
+
+	fs.journal = fs
+
+	return fs, nil
+}
+
+func (fs *FileStore) record(il *ItemList, changes []change) error {
+	return fs.save(applyChanges(il.snapshot(), changes))
+}
+
+func (fs *FileStore) save(s listSnapshot) error {
+	b, err := encodeSnapshot(s)
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(fs.path, b)
+	if err != nil {
+		return fmt.Errorf("error saving %v: %v", fs.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore_PersistsMutations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+
+	store, err := NewFileStore(path)
+	require.Nil(t, err)
+
+	_, err = store.CreateItem("abc")
+	require.Nil(t, err)
+	_, err = store.CreateItem("bcd")
+	require.Nil(t, err)
+	_, err = store.CreateItem("cdf")
+	require.Nil(t, err)
+	_, err = store.UpdateItem(1, "123")
+	require.Nil(t, err)
+	_, err = store.DeleteItem(2)
+	require.Nil(t, err)
+
+	reloaded, err := NewFileStore(path)
+	require.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "123", Version: 2}, {ID: 3, Item: "cdf", Version: 1}}, reloaded.ReadAll())
+
+	_, err = reloaded.DeleteAll()
+	require.Nil(t, err)
+
+	reloaded, err = NewFileStore(path)
+	require.Nil(t, err)
+	assert.Equal(t, []ItemAndID{}, reloaded.ReadAll())
+
+	item, err := reloaded.CreateItem("def")
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 4, Item: "def", Version: 1}, item)
+}
+
+func TestFileStore_CorruptFile(t *testing.T) {
+	valid, err := encodeSnapshot(listSnapshot{NextID: 2, Items: []ItemAndID{{ID: 1, Item: "abc", Version: 1}}})
+	require.Nil(t, err)
+
+	tampered := bytes.Replace(valid, []byte("abc"), []byte("abd"), 1)
+
+	duplicateIDs, err := encodeSnapshot(listSnapshot{NextID: 3, Items: []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 1, Item: "bcd", Version: 1}}})
+	require.Nil(t, err)
+
+	testTable := []struct {
+		name     string
+		contents []byte
+	}{
+		{
+			name:     "empty file",
+			contents: []byte{},
+		},
+		{
+			name:     "partially written",
+			contents: valid[:len(valid)/2],
+		},
+		{
+			name:     "checksum mismatch",
+			contents: tampered,
+		},
+		{
+			name:     "duplicate ids",
+			contents: duplicateIDs,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "items.json")
+			require.Nil(t, os.WriteFile(path, testCase.contents, 0644))
+
+			_, err := NewFileStore(path)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestFileStore_FailedWriteIsNotApplied(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	require.Nil(t, os.Mkdir(dir, 0755))
+
+	store, err := NewFileStore(filepath.Join(dir, "items.json"))
+	require.Nil(t, err)
+
+	_, err = store.CreateItem("abc")
+	require.Nil(t, err)
+
+	require.Nil(t, os.RemoveAll(dir))
+
+	_, err = store.CreateItem("bcd")
+	assert.NotNil(t, err)
+	_, err = store.DeleteAll()
+	assert.NotNil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}}, store.ReadAll())
+}
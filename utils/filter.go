@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// Filter is a parsed filter expression, see ParseFilter. Its nodes are the
+// types below, so that a store can translate the expression instead of
+// calling Match on every item.
+type Filter interface {
+	Match(item ItemAndID) bool
+}
+
+// Operator compares an item's field with a value.
+type Operator string
+
+const (
+	OpEqual          Operator = "="
+	OpNotEqual       Operator = "!="
+	OpLess           Operator = "<"
+	OpLessOrEqual    Operator = "<="
+	OpGreater        Operator = ">"
+	OpGreaterOrEqual Operator = ">="
+)
+
+// compare applies the operator to the result of comparing two values, which
+// is negative, zero or positive.
+func (op Operator) compare(c int) bool {
+	switch op {
+	case OpEqual:
+		return c == 0
+	case OpNotEqual:
+		return c != 0
+	case OpLess:
+		return c < 0
+	case OpLessOrEqual:
+		return c <= 0
+	case OpGreater:
+		return c > 0
+	case OpGreaterOrEqual:
+		return c >= 0
+	}
+
+	return false
+}
+
+// And matches items matched by all of its filters.
+type And []Filter
+
+func (f And) Match(item ItemAndID) bool {
+	for _, filter := range f {
+		if !filter.Match(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Or matches items matched by any of its filters.
+type Or []Filter
+
+func (f Or) Match(item ItemAndID) bool {
+	for _, filter := range f {
+		if filter.Match(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Not matches items its filter doesn't match.
+type Not struct {
+	Filter Filter
+}
+
+func (f Not) Match(item ItemAndID) bool {
+	return !f.Filter.Match(item)
+}
+
+// TextFilter matches items whose text or notes contain the text, ignoring
+// case.
+type TextFilter struct {
+	Text string
+}
+
+func (f TextFilter) Match(item ItemAndID) bool {
+	text := strings.ToLower(f.Text)
+	return strings.Contains(strings.ToLower(item.Item), text) || strings.Contains(strings.ToLower(item.Notes), text)
+}
+
+// StatusFilter matches items of the status.
+type StatusFilter struct {
+	Status Status
+}
+
+func (f StatusFilter) Match(item ItemAndID) bool {
+	return Query{Status: f.Status}.matches(item)
+}
+
+// TagFilter matches items carrying the tag, which has to be normalized.
+type TagFilter struct {
+	Tag string
+}
+
+func (f TagFilter) Match(item ItemAndID) bool {
+	return Query{Tags: []string{f.Tag}}.matchesTags(item)
+}
+
+// DueFilter compares the item's due date with Due; items without a due date
+// match no comparison. A nil Due stands for no due date, so OpEqual matches
+// items without a due date and OpNotEqual items with one.
+type DueFilter struct {
+	Op  Operator
+	Due *time.Time
+}
+
+func (f DueFilter) Match(item ItemAndID) bool {
+	if f.Due == nil {
+		return (item.Due == nil) == (f.Op == OpEqual)
+	}
+	if item.Due == nil {
+		return false
+	}
+
+	return f.Op.compare(compareTimes(*item.Due, *f.Due))
+}
+
+// PriorityFilter compares the item's priority with Priority, where more
+// pressing priorities are greater.
+type PriorityFilter struct {
+	Op       Operator
+	Priority Priority
+}
+
+func (f PriorityFilter) Match(item ItemAndID) bool {
+	return f.Op.compare(int(item.Priority) - int(f.Priority))
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,338 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SyntaxError is returned by ParseFilter for an expression it can't parse.
+type SyntaxError struct {
+	// Position is the 1-based position of the offending character.
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter has an error at position %v: %v", e.Position, e.Message)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+}
+
+// is reports whether the token is the keyword, which is case insensitive.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	tag:home and (due<2024-06-01 or priority>=high) and not "call"
+//
+// Terms are a field, an operator and a value; words and quoted strings on
+// their own match the item's text. Terms are combined with "and", which
+// can be left out, "or" and "not", and grouped with parentheses; "not" binds
+// tightest and "or" loosest. An empty expression returns a nil Filter.
+func ParseFilter(expression string) (Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := filterParser{tokens: tokens}
+	if p.peek().kind == tokenEnd {
+		return nil, nil
+	}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, &SyntaxError{Position: t.start, Message: fmt.Sprintf("unexpected %q", t.text)}
+	}
+
+	return filter, nil
+}
+
+func tokenize(expression string) ([]token, error) {
+	runes := []rune(expression)
+
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", start: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", start: start})
+			i++
+		case r == '"':
+			var text strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &SyntaxError{Position: start, Message: "string is not terminated"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), start: start})
+			i++
+		case strings.ContainsRune(":=<>!", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != ':' && r != '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &SyntaxError{Position: start, Message: `unexpected "!"`}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, start: start})
+			i += len(op)
+		default:
+			// a value may contain operator characters, as times do
+			stop := `()":=<>!`
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenOperator {
+				stop = `()"`
+			}
+
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(stop, runes[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[i:end]), start: start})
+			i = end
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, start: len(runes) + 1}), nil
+}
+
+type filterParser struct {
+	tokens []token
+	next   int
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+
+	return t
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	or := Or{filter}
+	for p.peek().is("or") {
+		p.take()
+
+		filter, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, filter)
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	filter, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	and := And{filter}
+	for {
+		t := p.peek()
+		if t.is("and") {
+			p.take()
+		} else if t.kind == tokenEnd || t.kind == tokenClose || t.is("or") {
+			break
+		}
+
+		filter, err = p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, filter)
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *filterParser) parseNot() (Filter, error) {
+	if p.peek().is("not") {
+		p.take()
+
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Filter: filter}, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *filterParser) parseTerm() (Filter, error) {
+	t := p.take()
+	switch {
+	case t.kind == tokenOpen:
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.take(); closing.kind != tokenClose {
+			return nil, &SyntaxError{Position: closing.start, Message: fmt.Sprintf("expected \")\" to close the \"(\" at position %v", t.start)}
+		}
+		return filter, nil
+	case t.kind == tokenString:
+		return TextFilter{Text: t.text}, nil
+	case t.kind == tokenEnd:
+		return nil, &SyntaxError{Position: t.start, Message: "unexpected end of the filter"}
+	case t.kind != tokenWord || t.is("and") || t.is("or"):
+		return nil, &SyntaxError{Position: t.start, Message: fmt.Sprintf("unexpected %q", t.text)}
+	case p.peek().kind != tokenOperator:
+		return TextFilter{Text: t.text}, nil
+	}
+
+	op := p.take()
+	value := p.take()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &SyntaxError{Position: value.start, Message: fmt.Sprintf("expected a value after %q", t.text+op.text)}
+	}
+
+	return parseFieldTerm(t, op, value)
+}
+
+// parseFieldTerm turns "field op value" into a filter; ":" is the same as
+// "=", except for text, where it means "contains".
+func parseFieldTerm(field, op, value token) (Filter, error) {
+	operator := Operator(op.text)
+	if op.text == ":" {
+		operator = OpEqual
+	}
+
+	equality := operator == OpEqual || operator == OpNotEqual
+	invalidOperator := &SyntaxError{Position: op.start, Message: fmt.Sprintf("operator (%v) can't be used with %v", op.text, strings.ToLower(field.text))}
+
+	var filter Filter
+	switch strings.ToLower(field.text) {
+	case "text":
+		if op.text != ":" {
+			return nil, invalidOperator
+		}
+		return TextFilter{Text: value.text}, nil
+	case "status":
+		if !equality {
+			return nil, invalidOperator
+		}
+		status, err := ParseStatus(value.text)
+		if err != nil || value.text == "" {
+			return nil, &SyntaxError{Position: value.start, Message: fmt.Sprintf("status (%v) has to be one of %v, %v or %v", value.text, StatusOpen, StatusDone, StatusAll)}
+		}
+		filter = StatusFilter{Status: status}
+	case "tag":
+		if !equality {
+			return nil, invalidOperator
+		}
+		tag, err := NormalizeTag(value.text)
+		if err != nil {
+			return nil, &SyntaxError{Position: value.start, Message: "tag is empty"}
+		}
+		filter = TagFilter{Tag: tag}
+	case "priority":
+		priority, err := ParsePriority(value.text)
+		if err != nil {
+			return nil, &SyntaxError{Position: value.start, Message: fmt.Sprintf("priority (%v) has to be one of none, low, medium, high, urgent or 0 to 4", value.text)}
+		}
+		return PriorityFilter{Op: operator, Priority: priority}, nil
+	case "due":
+		return parseDueTerm(operator, value, invalidOperator)
+	default:
+		return nil, &SyntaxError{Position: field.start, Message: fmt.Sprintf("unknown field (%v), has to be one of text, status, tag, due or priority", field.text)}
+	}
+
+	if operator == OpNotEqual {
+		return Not{Filter: filter}, nil
+	}
+	return filter, nil
+}
+
+// parseDueTerm accepts an RFC 3339 time, a date, which stands for the whole
+// day in UTC, or "none".
+func parseDueTerm(operator Operator, value token, invalidOperator error) (Filter, error) {
+	if strings.EqualFold(value.text, "none") {
+		if operator != OpEqual && operator != OpNotEqual {
+			return nil, invalidOperator
+		}
+		return DueFilter{Op: operator}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value.text); err == nil {
+		return DueFilter{Op: operator, Due: &t}, nil
+	}
+
+	day, err := time.Parse("2006-01-02", value.text)
+	if err != nil {
+		return nil, &SyntaxError{Position: value.start, Message: fmt.Sprintf("due (%v) has to be an RFC 3339 time, a date like 2006-01-02 or none", value.text)}
+	}
+	nextDay := day.AddDate(0, 0, 1)
+
+	switch operator {
+	case OpEqual:
+		return And{DueFilter{Op: OpGreaterOrEqual, Due: &day}, DueFilter{Op: OpLess, Due: &nextDay}}, nil
+	case OpNotEqual:
+		return Not{Filter: And{DueFilter{Op: OpGreaterOrEqual, Due: &day}, DueFilter{Op: OpLess, Due: &nextDay}}}, nil
+	case OpLess, OpGreaterOrEqual:
+		return DueFilter{Op: operator, Due: &day}, nil
+	case OpLessOrEqual:
+		return DueFilter{Op: OpLess, Due: &nextDay}, nil
+	default:
+		return DueFilter{Op: OpGreaterOrEqual, Due: &nextDay}, nil
+	}
+}
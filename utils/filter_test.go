@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name           string
+		expression     string
+		expectedFilter Filter
+		expectedError  error
+	}{
+		{
+			name:           "empty",
+			expression:     "  ",
+			expectedFilter: nil,
+		},
+		{
+			name:           "word",
+			expression:     "milk",
+			expectedFilter: TextFilter{Text: "milk"},
+		},
+		{
+			name:           "quoted string",
+			expression:     `"buy \"oat\" milk"`,
+			expectedFilter: TextFilter{Text: `buy "oat" milk`},
+		},
+		{
+			name:           "text field",
+			expression:     "text:milk",
+			expectedFilter: TextFilter{Text: "milk"},
+		},
+		{
+			name:           "status",
+			expression:     "status:open",
+			expectedFilter: StatusFilter{Status: StatusOpen},
+		},
+		{
+			name:           "tag",
+			expression:     `tag="On Call"`,
+			expectedFilter: TagFilter{Tag: "on call"},
+		},
+		{
+			name:           "not a tag",
+			expression:     "tag!=home",
+			expectedFilter: Not{Filter: TagFilter{Tag: "home"}},
+		},
+		{
+			name:           "priority",
+			expression:     "priority>=high",
+			expectedFilter: PriorityFilter{Op: OpGreaterOrEqual, Priority: PriorityHigh},
+		},
+		{
+			name:           "due time",
+			expression:     "due<2024-06-01T12:00:00Z",
+			expectedFilter: DueFilter{Op: OpLess, Due: &noon},
+		},
+		{
+			name:           "due day",
+			expression:     "due:2024-06-01",
+			expectedFilter: And{DueFilter{Op: OpGreaterOrEqual, Due: &day}, DueFilter{Op: OpLess, Due: &nextDay}},
+		},
+		{
+			name:           "due up to a day",
+			expression:     "due<=2024-06-01",
+			expectedFilter: DueFilter{Op: OpLess, Due: &nextDay},
+		},
+		{
+			name:           "due after a day",
+			expression:     "due>2024-06-01",
+			expectedFilter: DueFilter{Op: OpGreaterOrEqual, Due: &nextDay},
+		},
+		{
+			name:           "no due date",
+			expression:     "due:none",
+			expectedFilter: DueFilter{Op: OpEqual},
+		},
+		{
+			name:       "precedence",
+			expression: "a or not b and c",
+			expectedFilter: Or{
+				TextFilter{Text: "a"},
+				And{Not{Filter: TextFilter{Text: "b"}}, TextFilter{Text: "c"}},
+			},
+		},
+		{
+			name:       "implicit and",
+			expression: "tag:home milk OR Status:done",
+			expectedFilter: Or{
+				And{TagFilter{Tag: "home"}, TextFilter{Text: "milk"}},
+				StatusFilter{Status: StatusDone},
+			},
+		},
+		{
+			name:       "parentheses",
+			expression: "(a or b) and c",
+			expectedFilter: And{
+				Or{TextFilter{Text: "a"}, TextFilter{Text: "b"}},
+				TextFilter{Text: "c"},
+			},
+		},
+		{
+			name:           "keyword in quotes",
+			expression:     `"or"`,
+			expectedFilter: TextFilter{Text: "or"},
+		},
+		{
+			name:          "unknown field",
+			expression:    "tag:home and colour:red",
+			expectedError: &SyntaxError{Position: 14, Message: "unknown field (colour), has to be one of text, status, tag, due or priority"},
+		},
+		{
+			name:          "operator not allowed",
+			expression:    "tag<home",
+			expectedError: &SyntaxError{Position: 4, Message: "operator (<) can't be used with tag"},
+		},
+		{
+			name:          "invalid priority",
+			expression:    "priority>asap",
+			expectedError: &SyntaxError{Position: 10, Message: "priority (asap) has to be one of none, low, medium, high, urgent or 0 to 4"},
+		},
+		{
+			name:          "invalid due date",
+			expression:    "due<soon",
+			expectedError: &SyntaxError{Position: 5, Message: "due (soon) has to be an RFC 3339 time, a date like 2006-01-02 or none"},
+		},
+		{
+			name:          "invalid status",
+			expression:    "status:finished",
+			expectedError: &SyntaxError{Position: 8, Message: "status (finished) has to be one of open, done or all"},
+		},
+		{
+			name:          "missing value",
+			expression:    "tag:",
+			expectedError: &SyntaxError{Position: 5, Message: `expected a value after "tag:"`},
+		},
+		{
+			name:          "unclosed parenthesis",
+			expression:    "(a or b",
+			expectedError: &SyntaxError{Position: 8, Message: `expected ")" to close the "(" at position 1`},
+		},
+		{
+			name:          "stray parenthesis",
+			expression:    "a)",
+			expectedError: &SyntaxError{Position: 2, Message: `unexpected ")"`},
+		},
+		{
+			name:          "dangling operator",
+			expression:    "a and",
+			expectedError: &SyntaxError{Position: 6, Message: "unexpected end of the filter"},
+		},
+		{
+			name:          "unterminated string",
+			expression:    `tag:"home`,
+			expectedError: &SyntaxError{Position: 5, Message: "string is not terminated"},
+		},
+		{
+			name:          "positions count characters",
+			expression:    "café !",
+			expectedError: &SyntaxError{Position: 6, Message: `unexpected "!"`},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter, err := ParseFilter(testCase.expression)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedFilter, filter)
+		})
+	}
+}
+
+func TestParseFilter_ErrorKind(t *testing.T) {
+	_, err := ParseFilter("(")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	assert.Equal(t, "filter has an error at position 2: unexpected end of the filter", err.Error())
+}
+
+func TestFind_Filter(t *testing.T) {
+	june := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name        string
+		expression  string
+		expectedIDs []int
+	}{
+		{
+			name:        "text ignores case and searches notes",
+			expression:  "MILK",
+			expectedIDs: []int{1, 4},
+		},
+		{
+			name:        "status",
+			expression:  "status:done",
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "tag",
+			expression:  "tag:home",
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "priority comparison",
+			expression:  "priority>medium",
+			expectedIDs: []int{2, 3},
+		},
+		{
+			name:        "priority none",
+			expression:  "priority=none",
+			expectedIDs: []int{4},
+		},
+		{
+			name:        "due day",
+			expression:  "due:2024-06-01",
+			expectedIDs: []int{1},
+		},
+		{
+			name:        "due range",
+			expression:  "due>=2024-06-01 and due<2024-07-02",
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "without due date",
+			expression:  "due:none",
+			expectedIDs: []int{3, 4},
+		},
+		{
+			name:        "combined",
+			expression:  "(tag:home or priority:urgent) and not milk",
+			expectedIDs: []int{2, 3},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			il := NewItemList()
+			il.CreateItem("Buy milk", WithTags("home"), WithDue(june), WithPriority(PriorityLow))
+			il.CreateItem("Mow the lawn", WithTags("home"), WithDue(july), WithPriority(PriorityHigh))
+			il.CreateItem("Fix the pager", WithPriority(PriorityUrgent))
+			il.CompleteItem(3)
+			il.CreateItem("Groceries", WithNotes("eggs, milk"))
+
+			filter, err := ParseFilter(testCase.expression)
+			require.Nil(t, err)
+
+			assert.Equal(t, testCase.expectedIDs, itemIDs(il.Find(Query{Filter: filter})))
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder(" Due, -priority ")
+	require.Nil(t, err)
+	assert.Equal(t, SortOrder("due,-priority"), order)
+
+	_, err = ParseSortOrder("due,colour")
+	assert.Equal(t, NewFieldError(ErrValidation, "sort", "sort (due,colour) has to be a comma-separated list of id, item, done, due, priority or completed_at, each optionally prefixed with -"), err)
+}
+
+func TestFind_Sort(t *testing.T) {
+	june := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		order       SortOrder
+		expectedIDs []int
+	}{
+		{order: SortList, expectedIDs: []int{1, 2, 3, 4, 5}},
+		{order: "priority", expectedIDs: []int{3, 2, 4, 1, 5}},
+		{order: "-priority", expectedIDs: []int{1, 5, 2, 4, 3}},
+		{order: "due", expectedIDs: []int{2, 4, 1, 3, 5}},
+		{order: "-due", expectedIDs: []int{1, 2, 4, 3, 5}},
+		{order: "item", expectedIDs: []int{2, 3, 5, 1, 4}},
+		{order: "done,-id", expectedIDs: []int{5, 4, 3, 2, 1}},
+		{order: "priority,-due", expectedIDs: []int{3, 2, 4, 1, 5}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(string(testCase.order), func(t *testing.T) {
+			il := NewItemList()
+			il.CreateItem("mow", WithDue(july))
+			il.CreateItem("apples", WithDue(june), WithPriority(PriorityHigh))
+			il.CreateItem("Bake", WithPriority(PriorityUrgent))
+			il.CreateItem("pay", WithDue(june), WithPriority(PriorityHigh))
+			il.CreateItem("call")
+			il.CompleteItem(1)
+			il.CompleteItem(2)
+
+			assert.Equal(t, testCase.expectedIDs, itemIDs(il.Find(Query{Sort: testCase.order})))
+		})
+	}
+}
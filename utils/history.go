@@ -0,0 +1,214 @@
+package utils
+
+import "time"
+
+type RevisionOp string
+
+const (
+	RevisionCreated  RevisionOp = "created"
+	RevisionUpdated  RevisionOp = "updated"
+	RevisionDeleted  RevisionOp = "deleted"
+	RevisionRestored RevisionOp = "restored"
+	RevisionPurged   RevisionOp = "purged"
+	RevisionMoved    RevisionOp = "moved"
+)
+
+// Revision records a single change of an item. Old is the item before the
+// change and New the item after it; either is nil if the item didn't exist
+// in the list, i.e. on creation and deletion.
+type Revision struct {
+	ItemID int        `json:"item_id"`
+	At     time.Time  `json:"at"`
+	Op     RevisionOp `json:"op"`
+	// Actor is whoever made the change, if known, see ByActor.
+	Actor string     `json:"actor,omitempty"`
+	Old   *ItemAndID `json:"old,omitempty"`
+	New   *ItemAndID `json:"new,omitempty"`
+	// Before is the id of the item the item was placed in front of when it
+	// was moved, or put back into the list at its old place; 0 is the end of
+	// the list.
+	Before int `json:"before,omitempty"`
+}
+
+// ByActor attributes the revision made by a create, update or delete to the
+// actor.
+func ByActor(actor string) ItemOption {
+	return func(item *ItemAndID) {
+		item.actor = actor
+	}
+}
+
+// takeActor returns the actor set by ByActor and clears it, so that it isn't
+// stored with the item.
+func (item *ItemAndID) takeActor() string {
+	actor := item.actor
+	item.actor = ""
+
+	return actor
+}
+
+// actorOf returns the actor set by the options, for mutations that don't
+// apply options to an item.
+func actorOf(opts []ItemOption) string {
+	var item ItemAndID
+	for _, opt := range opts {
+		opt(&item)
+	}
+
+	return item.actor
+}
+
+// History returns the revisions of the item, oldest first. Items created
+// before revisions were recorded only have the revisions made since.
+func (il *ItemList) History(id int) ([]Revision, error) {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	if id < 1 {
+		return nil, NewError(ErrInvalidID, "id is less than 1")
+	}
+
+	revisions := []Revision{}
+	for _, revision := range il.history {
+		if revision.ItemID == id {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	if len(revisions) == 0 && indexOf(il.items, id) < 0 && indexOfTrashed(il.trash, id) < 0 {
+		return nil, NewError(ErrNotFound, "item with id (%v) does not exist", id)
+	}
+
+	return revisions, nil
+}
+
+// itemsAsOf reconstructs the list as it was at the given time by replaying
+// the history up to then. Must be called with il.m locked.
+func (il *ItemList) itemsAsOf(at time.Time) []ItemAndID {
+	return itemsAsOf(il.history, at)
+}
+
+func itemsAsOf(history []Revision, at time.Time) []ItemAndID {
+	s := listSnapshot{Items: []ItemAndID{}}
+
+	var changes []change
+	for _, revision := range history {
+		if revision.At.After(at) {
+			break
+		}
+
+		switch {
+		case revision.Op == RevisionMoved:
+			changes = append(changes, moveChange(revision.ItemID, revision.Before))
+		case revision.New != nil:
+			c := putChange(*revision.New)
+			c.Before = revision.Before
+			changes = append(changes, c)
+		default:
+			changes = append(changes, change{Op: opRemove, ID: revision.ItemID})
+		}
+	}
+
+	return applyChanges(s, changes).Items
+}
+
+// revisionChanges returns the changes recording a revision for every item
+// the changes put, trash, move or purge. Must be called with il.m locked, before
+// the changes are applied.
+func (il *ItemList) revisionChanges(actor string, changes []change) []change {
+	at := now().UTC()
+
+	// an item can be changed more than once in a commit, so keep track of
+	// its state as of the changes seen so far
+	type state struct {
+		item    *ItemAndID
+		trashed bool
+	}
+	states := map[int]state{}
+	lookup := func(id int) state {
+		if s, ok := states[id]; ok {
+			return s
+		}
+		if index := indexOf(il.items, id); index >= 0 {
+			item := il.items[index]
+			return state{item: &item}
+		}
+		if index := indexOfTrashed(il.trash, id); index >= 0 {
+			item := il.trash[index].ItemAndID
+			return state{item: &item, trashed: true}
+		}
+		return state{}
+	}
+
+	revisions := []change{}
+	for _, c := range changes {
+		revision := Revision{At: at, Actor: actor}
+
+		switch c.Op {
+		case opPut:
+			old := lookup(c.Item.ID)
+			revision.ItemID = c.Item.ID
+			revision.New = c.Item
+			revision.Before = c.Before
+			switch {
+			case old.item == nil:
+				revision.Op = RevisionCreated
+			case old.trashed:
+				revision.Op = RevisionRestored
+			default:
+				revision.Op = RevisionUpdated
+				revision.Old = old.item
+			}
+			states[c.Item.ID] = state{item: c.Item}
+		case opTrash:
+			old := lookup(c.Item.ID)
+			revision.ItemID = c.Item.ID
+			revision.Op = RevisionDeleted
+			revision.Old = old.item
+			states[c.Item.ID] = state{item: c.Item, trashed: true}
+		case opMove:
+			old := lookup(c.ID)
+			revision.ItemID = c.ID
+			revision.Op = RevisionMoved
+			revision.Old = old.item
+			revision.New = old.item
+			revision.Before = c.Before
+		case opPurge:
+			revision.ItemID = c.ID
+			revision.Op = RevisionPurged
+			states[c.ID] = state{}
+		case opRemove:
+			old := lookup(c.ID)
+			revision.ItemID = c.ID
+			revision.Op = RevisionDeleted
+			revision.Old = old.item
+			states[c.ID] = state{}
+		default:
+			continue
+		}
+
+		revisions = append(revisions, revisionChange(revision))
+	}
+
+	return revisions
+}
+
+// removeRevisions returns the history without the revisions of the item,
+// which hold its contents.
+func removeRevisions(history []Revision, id int) []Revision {
+	kept := []Revision{}
+	for _, revision := range history {
+		if revision.ItemID != id {
+			kept = append(kept, revision)
+		}
+	}
+
+	return kept
+}
+
+func copyHistory(history []Revision) []Revision {
+	historyCpy := make([]Revision, len(history))
+	copy(historyCpy, history)
+
+	return historyCpy
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2022, 11, 5, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	defer func() { now = time.Now }()
+
+	itemList := NewItemList()
+
+	now = func() time.Time { return at(1) }
+	itemList.CreateItem("abc", ByActor("alice"))
+	itemList.CreateItem("bcd")
+	now = func() time.Time { return at(2) }
+	itemList.UpdateItem(1, "123", ByActor("bob"))
+	now = func() time.Time { return at(3) }
+	itemList.DeleteItem(1, ByActor("carol"))
+	now = func() time.Time { return at(4) }
+	itemList.RestoreItem(1)
+
+	created := ItemAndID{ID: 1, Item: "abc", Version: 1}
+	updated := ItemAndID{ID: 1, Item: "123", Version: 2}
+	restored := ItemAndID{ID: 1, Item: "123", Version: 3}
+
+	revisions, err := itemList.History(1)
+	require.Nil(t, err)
+	assert.Equal(t, []Revision{
+		{ItemID: 1, At: at(1), Op: RevisionCreated, Actor: "alice", New: &created},
+		{ItemID: 1, At: at(2), Op: RevisionUpdated, Actor: "bob", Old: &created, New: &updated},
+		{ItemID: 1, At: at(3), Op: RevisionDeleted, Actor: "carol", Old: &updated},
+		{ItemID: 1, At: at(4), Op: RevisionRestored, New: &restored},
+	}, revisions)
+
+	// the actor isn't stored with the item
+	item, err := itemList.ReadItem(1)
+	require.Nil(t, err)
+	assert.Equal(t, restored, item)
+
+	_, err = itemList.History(3)
+	assert.Equal(t, NewError(ErrNotFound, "item with id (3) does not exist"), err)
+	_, err = itemList.History(0)
+	assert.Equal(t, NewError(ErrInvalidID, "id is less than 1"), err)
+}
+
+func TestFind_AsOf(t *testing.T) {
+	start := time.Date(2022, 11, 5, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	defer func() { now = time.Now }()
+
+	itemList := NewItemList()
+	now = func() time.Time { return at(1) }
+	itemList.CreateItem("abc")
+	itemList.CreateItem("bcd")
+	now = func() time.Time { return at(2) }
+	itemList.UpdateItem(1, "123")
+	itemList.CompleteItem(2)
+	now = func() time.Time { return at(3) }
+	itemList.DeleteItem(1)
+	itemList.CreateItem("cdf")
+	now = func() time.Time { return at(4) }
+	itemList.RestoreItem(1)
+
+	testTable := []struct {
+		name     string
+		asOf     time.Time
+		status   Status
+		expected []string
+	}{
+		{name: "before the first item", asOf: at(0), expected: []string{}},
+		{name: "after creation", asOf: at(1), expected: []string{"abc", "bcd"}},
+		{name: "between changes", asOf: at(1).Add(30 * time.Second), expected: []string{"abc", "bcd"}},
+		{name: "after update", asOf: at(2), expected: []string{"123", "bcd"}},
+		{name: "after update, open only", asOf: at(2), status: StatusOpen, expected: []string{"123"}},
+		{name: "after delete", asOf: at(3), expected: []string{"bcd", "cdf"}},
+		{name: "after restore", asOf: at(4), expected: []string{"bcd", "cdf", "123"}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			asOf := testCase.asOf
+
+			texts := []string{}
+			for _, item := range itemList.Find(Query{AsOf: &asOf, Status: testCase.status}) {
+				texts = append(texts, item.Item)
+			}
+			assert.Equal(t, testCase.expected, texts)
+		})
+	}
+}
+
+func TestFind_AsOfFollowsMoves(t *testing.T) {
+	start := time.Date(2022, 11, 5, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	defer func() { now = time.Now }()
+
+	itemList := NewItemList()
+	now = func() time.Time { return at(1) }
+	itemList.CreateItem("abc")
+	itemList.CreateItem("bcd")
+	itemList.CreateItem("cdf")
+	now = func() time.Time { return at(2) }
+	_, err := itemList.MoveItem(3, MoveToPosition(1))
+	require.Nil(t, err)
+	now = func() time.Time { return at(3) }
+	_, err = itemList.DeleteItem(1)
+	require.Nil(t, err)
+	now = func() time.Time { return at(4) }
+	_, err = itemList.Undo()
+	require.Nil(t, err)
+
+	for minutes, expected := range map[int][]int{1: {1, 2, 3}, 2: {3, 1, 2}, 3: {3, 2}, 4: {3, 1, 2}} {
+		asOf := at(minutes)
+		assert.Equal(t, expected, itemIDs(itemList.Find(Query{AsOf: &asOf})), "as of minute %v", minutes)
+	}
+
+	// the present as of now is the live list
+	asOf := at(5)
+	assert.Equal(t, itemIDs(itemList.Find(Query{})), itemIDs(itemList.Find(Query{AsOf: &asOf})))
+
+	history, err := itemList.History(3)
+	require.Nil(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, RevisionMoved, history[1].Op)
+	assert.Equal(t, 1, history[1].Before)
+}
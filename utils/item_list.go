@@ -2,123 +2,501 @@ package utils
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 )
 
+// now is the clock used for item timestamps; tests replace it.
+var now = time.Now
+
 type ItemList struct {
-	items []string
-	m     sync.RWMutex
+	items   []ItemAndID
+	trash   []TrashedItem
+	history []Revision
+	nextID  int
+	m       sync.RWMutex
+	journal journal
+	// undo and redo are the operations that can be undone and redone, most
+	// recent last; there are at most undoDepth of each.
+	undo      []operation
+	redo      []operation
+	undoDepth int
+	// index is the search index of items, kept up to date by apply.
+	index *searchIndex
+	// closed is set by Close; nothing can be committed after it.
+	closed bool
 }
 
 type ItemAndID struct {
 	ID   int    `json:"id"`
 	Item string `json:"item"`
+	// Version starts at 1 and is incremented by every change of the item.
+	Version int `json:"version,omitempty"`
+	// Notes is an optional markdown description of the item.
+	Notes       string     `json:"notes,omitempty"`
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	Priority    Priority   `json:"priority,omitempty"`
+	// Tags are normalized with NormalizeTag and kept sorted.
+	Tags []string `json:"tags,omitempty"`
+	// ParentID is the id of the item this item is a subtask of, or 0.
+	ParentID int `json:"parent_id,omitempty"`
+	// BlockedBy are the ids of the items that have to be completed before
+	// this one and Blocks the ids of the items waiting on this one, both
+	// sorted. They are kept in sync by AddBlocker and RemoveBlocker.
+	BlockedBy []int `json:"blocked_by,omitempty"`
+	Blocks    []int `json:"blocks,omitempty"`
+	// Recurrence is the normalized rule of a recurring item, see
+	// ParseRecurrence, and RecurrenceStart the due date its series is
+	// counted from.
+	Recurrence      string     `json:"recurrence,omitempty"`
+	RecurrenceStart *time.Time `json:"recurrence_start,omitempty"`
+
+	// actor is set by ByActor for the duration of a mutation only.
+	actor string
+}
+
+// ItemOption sets an optional attribute of an item on create or update.
+type ItemOption func(item *ItemAndID)
+
+// VersionMismatchError is returned when an item isn't at the version given
+// with IfVersion.
+type VersionMismatchError struct {
+	ID       int
+	Version  int
+	Expected int
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("item with id (%v) is at version (%v), not (%v)", e.ID, e.Version, e.Expected)
+}
+
+func (e *VersionMismatchError) Unwrap() error {
+	return ErrVersionMismatch
+}
+
+// IfVersion makes an update or delete fail with a VersionMismatchError
+// unless the item is at one of the given versions; without any it always
+// fails.
+func IfVersion(versions ...int) ItemOption {
+	return func(item *ItemAndID) {
+		for _, version := range versions {
+			if item.Version == version {
+				return
+			}
+		}
+
+		item.Version = -1
+		if len(versions) > 0 {
+			item.Version = versions[0]
+		}
+	}
+}
+
+// WithDue sets the item's due date; a recurring item's series restarts at
+// the new date.
+func WithDue(due time.Time) ItemOption {
+	return func(item *ItemAndID) {
+		due = due.UTC()
+		item.Due = &due
+		item.RecurrenceStart = nil
+	}
+}
+
+// WithoutDue clears the item's due date.
+func WithoutDue() ItemOption {
+	return func(item *ItemAndID) {
+		item.Due = nil
+		item.RecurrenceStart = nil
+	}
+}
+
+// WithNotes sets the item's notes; "" clears them.
+func WithNotes(notes string) ItemOption {
+	return func(item *ItemAndID) {
+		item.Notes = notes
+	}
+}
+
+// WithPriority sets the item's priority; PriorityNone clears it.
+func WithPriority(priority Priority) ItemOption {
+	return func(item *ItemAndID) {
+		item.Priority = priority
+	}
+}
+
+// IsOverdue reports whether the item is still open after its due date.
+func (item ItemAndID) IsOverdue(at time.Time) bool {
+	return !item.Done && item.Due != nil && item.Due.Before(at)
 }
 
 func NewItemList() *ItemList {
 	return &ItemList{
-		items: []string{},
+		items:     []ItemAndID{},
+		nextID:    1,
+		undoDepth: DefaultUndoDepth,
+		index:     newSearchIndex(nil),
 	}
 }
 
-func (il *ItemList) CreateItem(item string) ItemAndID {
+func (il *ItemList) CreateItem(item string, opts ...ItemOption) (ItemAndID, error) {
 	il.m.Lock()
 	defer il.m.Unlock()
 
-	il.items = append(il.items, item)
-
-	return ItemAndID{
+	newItem := ItemAndID{
 		Item: item,
-		ID:   len(il.items),
+		ID:   il.nextID,
 	}
-}
+	for _, opt := range opts {
+		opt(&newItem)
+	}
+	newItem.anchorRecurrence()
+	actor := newItem.takeActor()
 
-func (il *ItemList) ReadItem(index int) (ItemAndID, error) {
-	adjustedIndex, err := il.validateIndex(index)
+	err := il.validateParent(newItem)
 	if err != nil {
 		return ItemAndID{}, err
 	}
 
+	err = il.commitAs(fmt.Sprintf("create item %v", newItem.ID), actor, il.put(&newItem))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return newItem, nil
+}
+
+func (il *ItemList) ReadItem(id int) (ItemAndID, error) {
 	il.m.RLock()
 	defer il.m.RUnlock()
 
-	item := il.items[adjustedIndex]
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
 
-	return ItemAndID{
-		Item: item,
-		ID:   index,
-	}, nil
+	return il.items[index], nil
 }
 
 func (il *ItemList) ReadAll() []ItemAndID {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	return copyItems(il.items)
+}
+
+// UpdateItem replaces the item's text, unless newItem is empty, and applies
+// opts; attributes without an option are left unchanged. An update that
+// doesn't change anything keeps the item's version.
+func (il *ItemList) UpdateItem(id int, newItem string, opts ...ItemOption) (ItemAndID, error) {
 	il.m.Lock()
 	defer il.m.Unlock()
 
-	return itemsWithID(il.items)
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	updated := il.items[index]
+	if newItem != "" {
+		updated.Item = newItem
+	}
+	for _, opt := range opts {
+		opt(&updated)
+	}
+	updated.anchorRecurrence()
+	actor := updated.takeActor()
+
+	err = checkVersion(il.items[index], opts)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	err = il.validateParent(updated)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	if reflect.DeepEqual(updated, il.items[index]) {
+		return updated, nil
+	}
+
+	err = il.commitAs(fmt.Sprintf("update item %v", id), actor, il.put(&updated))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return updated, nil
 }
 
-func (il *ItemList) UpdateItem(index int, newItem string) (ItemAndID, error) {
-	adjustedIndex, err := il.validateIndex(index)
+// CompleteItem marks the item as done. Completing an item that is already
+// done keeps its original completion time. Completing a recurring item
+// creates its next occurrence as a new item.
+func (il *ItemList) CompleteItem(id int) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
 	if err != nil {
 		return ItemAndID{}, err
 	}
 
+	completed := il.items[index]
+	if completed.Done {
+		return completed, nil
+	}
+
+	completedAt := now().UTC()
+	completed.Done = true
+	completed.CompletedAt = &completedAt
+
+	changes := []change{il.put(&completed)}
+	if next, ok := completed.nextOccurrence(completedAt); ok {
+		next.ID = il.nextID
+		changes = append(changes, il.put(&next))
+	}
+
+	err = il.commit(fmt.Sprintf("complete item %v", id), changes...)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return completed, nil
+}
+
+func (il *ItemList) ReopenItem(id int) (ItemAndID, error) {
 	il.m.Lock()
 	defer il.m.Unlock()
 
-	il.items[adjustedIndex] = newItem
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	reopened := il.items[index]
+	if !reopened.Done {
+		return reopened, nil
+	}
+
+	reopened.Done = false
+	reopened.CompletedAt = nil
 
-	return ItemAndID{
-		Item: newItem,
-		ID:   index,
-	}, nil
+	err = il.commit(fmt.Sprintf("reopen item %v", id), il.put(&reopened))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return reopened, nil
 }
 
-func (il *ItemList) DeleteItem(index int) (ItemAndID, error) {
-	adjustedIndex, err := il.validateIndex(index)
+// DeleteItem moves a single item to the trash; items with subtasks have to be
+// deleted with DeleteTree. The only options that apply are IfVersion and
+// ByActor.
+func (il *ItemList) DeleteItem(id int, opts ...ItemOption) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	err = checkVersion(il.items[index], opts)
 	if err != nil {
 		return ItemAndID{}, err
 	}
 
+	if il.hasChildren(id) {
+		return ItemAndID{}, NewError(ErrConflict, "item with id (%v) has subtasks", id)
+	}
+
+	itemToDelete := il.items[index]
+
+	deleted := []ItemAndID{itemToDelete}
+	changes := append(il.unlinkChanges(deleted), il.trashChanges(deleted)...)
+	err = il.commitAs(fmt.Sprintf("delete item %v", id), actorOf(opts), changes...)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return itemToDelete, nil
+}
+
+// DeleteAll moves every item to the trash.
+func (il *ItemList) DeleteAll() ([]ItemAndID, error) {
 	il.m.Lock()
 	defer il.m.Unlock()
 
-	itemToDelete := il.items[adjustedIndex]
-	il.items = append(il.items[:adjustedIndex], il.items[adjustedIndex+1:]...)
+	deleted := copyItems(il.items)
 
-	return ItemAndID{
-		Item: itemToDelete,
-		ID:   index,
-	}, nil
+	err := il.commit("delete all items", il.trashChanges(deleted)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
 }
 
-func (il *ItemList) DeleteAll() []ItemAndID {
-	listCpy := il.items
+// Find returns the items matching the query in the query's sort order.
+func (il *ItemList) Find(q Query) []ItemAndID {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	source := il.items
+	if q.AsOf != nil {
+		source = il.itemsAsOf(*q.AsOf)
+	}
 
+	return q.find(source)
+}
+
+// Close marks the list as closed, so that every mutation made after it fails.
+func (il *ItemList) Close() error {
 	il.m.Lock()
 	defer il.m.Unlock()
 
-	il.items = il.items[:0]
-	return itemsWithID(listCpy)
+	il.closed = true
+
+	return nil
 }
 
-func (il *ItemList) validateIndex(index int) (adjustedIndex int, err error) {
-	if index < 1 {
-		return 0, fmt.Errorf("id is less than 1")
-	} else if index > len(il.items) {
-		return 0, fmt.Errorf("id (%v) is more than the number of items (%v)", index, len(il.items))
+func (il *ItemList) Count() int {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	return len(il.items)
+}
+
+// commit hands the changes to the journal, if any, and applies them once
+// they have been recorded. Must be called with il.m locked for writing.
+// Unless the description is empty, the changes can be undone as the
+// operation it describes, which is recorded along with them.
+func (il *ItemList) commit(description string, changes ...change) error {
+	return il.commitAs(description, "", changes...)
+}
+
+// commitAs commits the changes along with the revisions they make, which
+// are attributed to the actor if it isn't empty.
+func (il *ItemList) commitAs(description string, actor string, changes ...change) error {
+	if il.closed {
+		return NewError(ErrNotFound, "the list has been closed")
+	}
+
+	if description != "" && il.undoDepth > 0 {
+		ids := changedIDs(changes)
+		op := operation{Description: description, Before: il.statesOf(ids), After: il.statesAfter(changes, ids)}
+		changes = append(changes, change{Op: opPushUndo, Operation: &op, Depth: il.undoDepth})
 	}
-	return index - 1, nil
+
+	changes = append(changes, il.revisionChanges(actor, changes)...)
+
+	if il.journal != nil {
+		err := il.journal.record(il, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	il.apply(changes)
+
+	return nil
+}
+
+// apply applies the changes to the list's state in place.
+func (il *ItemList) apply(changes []change) {
+	s := applyChanges(listSnapshot{NextID: il.nextID, Items: il.items, Trash: il.trash, History: il.history, Undo: il.undo, Redo: il.redo}, changes)
+	il.items, il.trash, il.history, il.nextID = s.Items, s.Trash, s.History, s.NextID
+	il.undo, il.redo = s.Undo, s.Redo
+
+	if il.index != nil {
+		il.index.update(il.items, changes)
+	}
+}
+
+// checkVersion applies the options to a copy of the item and fails if that
+// changed its version, i.e. if IfVersion was given another version.
+func checkVersion(item ItemAndID, opts []ItemOption) error {
+	checked := item
+	for _, opt := range opts {
+		opt(&checked)
+	}
+
+	if checked.Version != item.Version {
+		return &VersionMismatchError{ID: item.ID, Version: item.Version, Expected: checked.Version}
+	}
+
+	return nil
+}
+
+// put returns the change that stores the item, incrementing its version.
+// Every change of an item goes through put so that its version is bumped
+// exactly once per commit.
+func (il *ItemList) put(item *ItemAndID) change {
+	item.Version++
+
+	return putChange(*item)
+}
+
+// modify applies fn to a copy of the item and commits the result as the
+// described operation, unless fn returns an error or leaves the item
+// unchanged.
+func (il *ItemList) modify(id int, description string, fn func(item *ItemAndID) error) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	modified := il.items[index]
+	err = fn(&modified)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	if reflect.DeepEqual(modified, il.items[index]) {
+		return modified, nil
+	}
+
+	err = il.commit(description, il.put(&modified))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return modified, nil
+}
+
+// indexOf returns the position of the item with the given id; IDs are
+// handed out by CreateItem and never reused, so they are not positions.
+func (il *ItemList) indexOf(id int) (int, error) {
+	if id < 1 {
+		return 0, NewError(ErrInvalidID, "id is less than 1")
+	}
+
+	index := indexOf(il.items, id)
+	if index >= 0 {
+		return index, nil
+	}
+
+	return 0, NewError(ErrNotFound, "item with id (%v) does not exist", id)
 }
 
-func itemsWithID(listCpy []string) []ItemAndID {
-	items := []ItemAndID{}
-	for i, item := range listCpy {
-		items = append(items, ItemAndID{
-			ID:   i + 1,
-			Item: item,
-		})
+func indexOf(items []ItemAndID, id int) int {
+	for i, item := range items {
+		if item.ID == id {
+			return i
+		}
 	}
 
-	return items
+	return -1
+}
+
+func copyItems(items []ItemAndID) []ItemAndID {
+	itemsCpy := make([]ItemAndID, len(items))
+	copy(itemsCpy, items)
+
+	return itemsCpy
 }
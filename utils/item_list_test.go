@@ -1,9 +1,10 @@
 package utils
 
 import (
-	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestCreateItem(t *testing.T) {
@@ -19,29 +20,32 @@ func TestCreateItem(t *testing.T) {
 			name:             "no existing items",
 			itemList:         NewItemList(),
 			itemToAdd:        "hello",
-			expectedItemList: &ItemList{items: []string{"hello"}},
+			expectedItemList: itemListOf("hello"),
 			expectedResponse: ItemAndID{
-				Item: "hello",
-				ID:   1,
+				Item:    "hello",
+				ID:      1,
+				Version: 1,
 			},
 		},
 		{
 			name:             "existing item",
-			itemList:         &ItemList{items: []string{"hello"}},
+			itemList:         itemListOf("hello"),
 			itemToAdd:        "world",
-			expectedItemList: &ItemList{items: []string{"hello", "world"}},
+			expectedItemList: itemListOf("hello", "world"),
 			expectedResponse: ItemAndID{
-				Item: "world",
-				ID:   2,
+				Item:    "world",
+				ID:      2,
+				Version: 1,
 			},
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			item := testCase.itemList.CreateItem(testCase.itemToAdd)
+			item, err := testCase.itemList.CreateItem(testCase.itemToAdd)
 
-			assert.Equal(t, testCase.expectedItemList, testCase.itemList)
+			assert.Nil(t, err)
+			assertListState(t, testCase.expectedItemList, testCase.itemList)
 			assert.Equal(t, testCase.expectedResponse, item)
 		})
 	}
@@ -57,35 +61,37 @@ func TestReadItem(t *testing.T) {
 	}{
 		{
 			name:             "id is 0",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			itemList:         itemListOf("abc", "bcd"),
 			index:            0,
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id is less than 1"),
+			expectedError:    NewError(ErrInvalidID, "id is less than 1"),
 		},
 		{
-			name:             "id is more than length",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			name:             "id does not exist",
+			itemList:         itemListOf("abc", "bcd"),
 			index:            3,
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id (%v) is more than the number of items (%v)", 3, 2),
+			expectedError:    NewError(ErrNotFound, "item with id (%v) does not exist", 3),
 		},
 		{
 			name:     "id is 1",
-			itemList: &ItemList{items: []string{"abc", "bcd"}},
+			itemList: itemListOf("abc", "bcd"),
 			index:    1,
 			expectedResponse: ItemAndID{
-				Item: "abc",
-				ID:   1,
+				Item:    "abc",
+				ID:      1,
+				Version: 1,
 			},
 			expectedError: nil,
 		},
 		{
 			name:     "id is length",
-			itemList: &ItemList{items: []string{"abc", "bcd"}},
+			itemList: itemListOf("abc", "bcd"),
 			index:    2,
 			expectedResponse: ItemAndID{
-				Item: "bcd",
-				ID:   2,
+				Item:    "bcd",
+				ID:      2,
+				Version: 1,
 			},
 			expectedError: nil,
 		},
@@ -114,29 +120,33 @@ func TestReadAll(t *testing.T) {
 		},
 		{
 			name:     "one item",
-			itemList: &ItemList{items: []string{"abc"}},
+			itemList: itemListOf("abc"),
 			expectedResponse: []ItemAndID{
 				{
-					Item: "abc",
-					ID:   1,
+					Item:    "abc",
+					ID:      1,
+					Version: 1,
 				},
 			},
 		},
 		{
 			name:     "multiple items",
-			itemList: &ItemList{items: []string{"abc", "{hello:world}", "123"}},
+			itemList: itemListOf("abc", "{hello:world}", "123"),
 			expectedResponse: []ItemAndID{
 				{
-					Item: "abc",
-					ID:   1,
+					Item:    "abc",
+					ID:      1,
+					Version: 1,
 				},
 				{
-					Item: "{hello:world}",
-					ID:   2,
+					Item:    "{hello:world}",
+					ID:      2,
+					Version: 1,
 				},
 				{
-					Item: "123",
-					ID:   3,
+					Item:    "123",
+					ID:      3,
+					Version: 1,
 				},
 			},
 		},
@@ -163,43 +173,45 @@ func TestUpdateItem(t *testing.T) {
 	}{
 		{
 			name:             "id is 0",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			itemList:         itemListOf("abc", "bcd"),
 			index:            0,
 			update:           "",
-			expectedItemList: &ItemList{items: []string{"abc", "bcd"}},
+			expectedItemList: itemListOf("abc", "bcd"),
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id is less than 1"),
+			expectedError:    NewError(ErrInvalidID, "id is less than 1"),
 		},
 		{
-			name:             "id is more than length",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			name:             "id does not exist",
+			itemList:         itemListOf("abc", "bcd"),
 			index:            3,
 			update:           "",
-			expectedItemList: &ItemList{items: []string{"abc", "bcd"}},
+			expectedItemList: itemListOf("abc", "bcd"),
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id (%v) is more than the number of items (%v)", 3, 2),
+			expectedError:    NewError(ErrNotFound, "item with id (%v) does not exist", 3),
 		},
 		{
 			name:             "id is 1",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			itemList:         itemListOf("abc", "bcd"),
 			index:            1,
 			update:           "123",
-			expectedItemList: &ItemList{items: []string{"123", "bcd"}},
+			expectedItemList: &ItemList{items: []ItemAndID{{ID: 1, Item: "123", Version: 2}, {ID: 2, Item: "bcd", Version: 1}}, nextID: 3},
 			expectedResponse: ItemAndID{
-				Item: "123",
-				ID:   1,
+				Item:    "123",
+				ID:      1,
+				Version: 2,
 			},
 			expectedError: nil,
 		},
 		{
 			name:             "id is length",
-			itemList:         &ItemList{items: []string{"123", "bcd"}},
+			itemList:         itemListOf("123", "bcd"),
 			index:            2,
 			update:           "456",
-			expectedItemList: &ItemList{items: []string{"123", "456"}},
+			expectedItemList: &ItemList{items: []ItemAndID{{ID: 1, Item: "123", Version: 1}, {ID: 2, Item: "456", Version: 2}}, nextID: 3},
 			expectedResponse: ItemAndID{
-				Item: "456",
-				ID:   2,
+				Item:    "456",
+				ID:      2,
+				Version: 2,
 			},
 			expectedError: nil,
 		},
@@ -210,13 +222,17 @@ func TestUpdateItem(t *testing.T) {
 			item, err := testCase.itemList.UpdateItem(testCase.index, testCase.update)
 
 			assert.Equal(t, testCase.expectedError, err)
-			assert.Equal(t, testCase.expectedItemList, testCase.itemList)
+			assertListState(t, testCase.expectedItemList, testCase.itemList)
 			assert.Equal(t, testCase.expectedResponse, item)
 		})
 	}
 }
 
 func TestDeleteItem(t *testing.T) {
+	deletedAt := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+	now = func() time.Time { return deletedAt }
+	defer func() { now = time.Now }()
+
 	testTable := []struct {
 		name             string
 		itemList         *ItemList
@@ -227,50 +243,65 @@ func TestDeleteItem(t *testing.T) {
 	}{
 		{
 			name:             "id is 0",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			itemList:         itemListOf("abc", "bcd"),
 			id:               0,
-			expectedItemList: &ItemList{items: []string{"abc", "bcd"}},
+			expectedItemList: itemListOf("abc", "bcd"),
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id is less than 1"),
+			expectedError:    NewError(ErrInvalidID, "id is less than 1"),
 		},
 		{
-			name:             "id is more than length",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
+			name:             "id does not exist",
+			itemList:         itemListOf("abc", "bcd"),
 			id:               3,
-			expectedItemList: &ItemList{items: []string{"abc", "bcd"}},
+			expectedItemList: itemListOf("abc", "bcd"),
 			expectedResponse: ItemAndID{},
-			expectedError:    fmt.Errorf("id (%v) is more than the number of items (%v)", 3, 2),
+			expectedError:    NewError(ErrNotFound, "item with id (%v) does not exist", 3),
 		},
 		{
-			name:             "id is 1",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
-			id:               1,
-			expectedItemList: &ItemList{items: []string{"bcd"}},
+			name:     "id is 1",
+			itemList: itemListOf("abc", "bcd"),
+			id:       1,
+			expectedItemList: &ItemList{
+				items:  []ItemAndID{{ID: 2, Item: "bcd", Version: 1}},
+				trash:  []TrashedItem{{ItemAndID: ItemAndID{ID: 1, Item: "abc", Version: 1}, DeletedAt: deletedAt}},
+				nextID: 3,
+			},
 			expectedResponse: ItemAndID{
-				Item: "abc",
-				ID:   1,
+				Item:    "abc",
+				ID:      1,
+				Version: 1,
 			},
 			expectedError: nil,
 		},
 		{
-			name:             "id is length",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
-			id:               2,
-			expectedItemList: &ItemList{items: []string{"abc"}},
+			name:     "id is length",
+			itemList: itemListOf("abc", "bcd"),
+			id:       2,
+			expectedItemList: &ItemList{
+				items:  []ItemAndID{{ID: 1, Item: "abc", Version: 1}},
+				trash:  []TrashedItem{{ItemAndID: ItemAndID{ID: 2, Item: "bcd", Version: 1}, DeletedAt: deletedAt}},
+				nextID: 3,
+			},
 			expectedResponse: ItemAndID{
-				Item: "bcd",
-				ID:   2,
+				Item:    "bcd",
+				ID:      2,
+				Version: 1,
 			},
 			expectedError: nil,
 		},
 		{
-			name:             "id is in middle",
-			itemList:         &ItemList{items: []string{"abc", "bcd", "cdf", "123"}},
-			id:               3,
-			expectedItemList: &ItemList{items: []string{"abc", "bcd", "123"}},
+			name:     "id is in middle",
+			itemList: itemListOf("abc", "bcd", "cdf", "123"),
+			id:       3,
+			expectedItemList: &ItemList{
+				items:  []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "bcd", Version: 1}, {ID: 4, Item: "123", Version: 1}},
+				trash:  []TrashedItem{{ItemAndID: ItemAndID{ID: 3, Item: "cdf", Version: 1}, DeletedAt: deletedAt}},
+				nextID: 5,
+			},
 			expectedResponse: ItemAndID{
-				Item: "cdf",
-				ID:   3,
+				Item:    "cdf",
+				ID:      3,
+				Version: 1,
 			},
 			expectedError: nil,
 		},
@@ -281,13 +312,17 @@ func TestDeleteItem(t *testing.T) {
 			item, err := testCase.itemList.DeleteItem(testCase.id)
 
 			assert.Equal(t, testCase.expectedError, err)
-			assert.Equal(t, testCase.expectedItemList, testCase.itemList)
+			assertListState(t, testCase.expectedItemList, testCase.itemList)
 			assert.Equal(t, testCase.expectedResponse, item)
 		})
 	}
 }
 
 func TestDeleteAll(t *testing.T) {
+	deletedAt := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+	now = func() time.Time { return deletedAt }
+	defer func() { now = time.Now }()
+
 	testTable := []struct {
 		name             string
 		itemList         *ItemList
@@ -296,45 +331,67 @@ func TestDeleteAll(t *testing.T) {
 	}{
 		{
 			name:             "0 items",
-			itemList:         &ItemList{items: []string{}},
-			expectedItemList: &ItemList{items: []string{}},
+			itemList:         itemListOf(),
+			expectedItemList: itemListOf(),
 			expectedResponse: []ItemAndID{},
 		},
 		{
-			name:             "2 items",
-			itemList:         &ItemList{items: []string{"abc", "bcd"}},
-			expectedItemList: &ItemList{items: []string{}},
+			name:     "2 items",
+			itemList: itemListOf("abc", "bcd"),
+			expectedItemList: &ItemList{
+				items: []ItemAndID{},
+				trash: []TrashedItem{
+					{ItemAndID: ItemAndID{ID: 1, Item: "abc", Version: 1}, DeletedAt: deletedAt},
+					{ItemAndID: ItemAndID{ID: 2, Item: "bcd", Version: 1}, DeletedAt: deletedAt},
+				},
+				nextID: 3,
+			},
 			expectedResponse: []ItemAndID{
 				{
-					ID:   1,
-					Item: "abc",
+					ID:      1,
+					Item:    "abc",
+					Version: 1,
 				},
 				{
-					ID:   2,
-					Item: "bcd",
+					ID:      2,
+					Item:    "bcd",
+					Version: 1,
 				},
 			},
 		},
 		{
-			name:             "4 items",
-			itemList:         &ItemList{items: []string{"abc", "bcd", "cdf", "123"}},
-			expectedItemList: &ItemList{items: []string{}},
+			name:     "4 items",
+			itemList: itemListOf("abc", "bcd", "cdf", "123"),
+			expectedItemList: &ItemList{
+				items: []ItemAndID{},
+				trash: []TrashedItem{
+					{ItemAndID: ItemAndID{ID: 1, Item: "abc", Version: 1}, DeletedAt: deletedAt},
+					{ItemAndID: ItemAndID{ID: 2, Item: "bcd", Version: 1}, DeletedAt: deletedAt},
+					{ItemAndID: ItemAndID{ID: 3, Item: "cdf", Version: 1}, DeletedAt: deletedAt},
+					{ItemAndID: ItemAndID{ID: 4, Item: "123", Version: 1}, DeletedAt: deletedAt},
+				},
+				nextID: 5,
+			},
 			expectedResponse: []ItemAndID{
 				{
-					ID:   1,
-					Item: "abc",
+					ID:      1,
+					Item:    "abc",
+					Version: 1,
 				},
 				{
-					ID:   2,
-					Item: "bcd",
+					ID:      2,
+					Item:    "bcd",
+					Version: 1,
 				},
 				{
-					ID:   3,
-					Item: "cdf",
+					ID:      3,
+					Item:    "cdf",
+					Version: 1,
 				},
 				{
-					ID:   4,
-					Item: "123",
+					ID:      4,
+					Item:    "123",
+					Version: 1,
 				},
 			},
 		},
@@ -342,10 +399,160 @@ func TestDeleteAll(t *testing.T) {
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			items := testCase.itemList.DeleteAll()
+			items, err := testCase.itemList.DeleteAll()
 
-			assert.Equal(t, testCase.expectedItemList, testCase.itemList)
+			assert.Nil(t, err)
+			assertListState(t, testCase.expectedItemList, testCase.itemList)
 			assert.Equal(t, testCase.expectedResponse, items)
 		})
 	}
 }
+
+func TestStableIDs(t *testing.T) {
+	itemList := itemListOf("abc", "bcd", "cdf")
+
+	_, err := itemList.DeleteItem(2)
+	assert.Nil(t, err)
+
+	item, err := itemList.ReadItem(3)
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 3, Item: "cdf", Version: 1}, item)
+
+	_, err = itemList.ReadItem(2)
+	assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 2), err)
+
+	item, err = itemList.CreateItem("def")
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 4, Item: "def", Version: 1}, item)
+
+	_, err = itemList.DeleteAll()
+	assert.Nil(t, err)
+
+	item, err = itemList.CreateItem("efg")
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 5, Item: "efg", Version: 1}, item)
+	assert.Equal(t, []ItemAndID{{ID: 5, Item: "efg", Version: 1}}, itemList.ReadAll())
+}
+
+func itemListOf(items ...string) *ItemList {
+	itemList := NewItemList()
+	for _, item := range items {
+		itemList.CreateItem(item)
+	}
+
+	return itemList
+}
+
+// assertListState compares the items, trash and next ID of the lists, but not
+// their history, which is tested on its own.
+func assertListState(t *testing.T, expected *ItemList, actual *ItemList) {
+	assert.Equal(t, expected.items, actual.items)
+	assert.Equal(t, expected.trash, actual.trash)
+	assert.Equal(t, expected.nextID, actual.nextID)
+}
+
+func TestCompleteAndReopenItem(t *testing.T) {
+	completedAt := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+	now = func() time.Time { return completedAt }
+	defer func() { now = time.Now }()
+
+	itemList := itemListOf("abc", "bcd")
+
+	item, err := itemList.CompleteItem(2)
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 2, Item: "bcd", Version: 2, Done: true, CompletedAt: &completedAt}, item)
+
+	now = func() time.Time { return completedAt.Add(time.Hour) }
+	item, err = itemList.CompleteItem(2)
+	assert.Nil(t, err)
+	assert.Equal(t, &completedAt, item.CompletedAt)
+
+	assert.Equal(t, []ItemAndID{{ID: 2, Item: "bcd", Version: 2, Done: true, CompletedAt: &completedAt}}, itemList.Find(Query{Status: StatusDone}))
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}}, itemList.Find(Query{Status: StatusOpen}))
+	assert.Equal(t, 2, len(itemList.Find(Query{})))
+
+	item, err = itemList.ReopenItem(2)
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 2, Item: "bcd", Version: 3}, item)
+
+	_, err = itemList.CompleteItem(3)
+	assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 3), err)
+	_, err = itemList.ReopenItem(0)
+	assert.Equal(t, NewError(ErrInvalidID, "id is less than 1"), err)
+}
+
+func TestFind_Due(t *testing.T) {
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	middle := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	itemList := NewItemList()
+	itemList.CreateItem("early", WithDue(early))
+	itemList.CreateItem("late", WithDue(late))
+	itemList.CreateItem("no due date")
+
+	testTable := []struct {
+		name        string
+		query       Query
+		expectedIDs []int
+	}{
+		{
+			name:        "everything",
+			query:       Query{},
+			expectedIDs: []int{1, 2, 3},
+		},
+		{
+			name:        "has due",
+			query:       Query{HasDue: true},
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "before",
+			query:       Query{DueBefore: &middle},
+			expectedIDs: []int{1},
+		},
+		{
+			name:        "after",
+			query:       Query{DueAfter: &middle},
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "bounds are exclusive",
+			query:       Query{DueAfter: &early, DueBefore: &late},
+			expectedIDs: []int{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ids := []int{}
+			for _, item := range itemList.Find(testCase.query) {
+				ids = append(ids, item.ID)
+			}
+
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestIfVersion(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("abc")
+	itemList.UpdateItem(1, "def")
+
+	_, err := itemList.UpdateItem(1, "ghi", IfVersion(1))
+	assert.Equal(t, &VersionMismatchError{ID: 1, Version: 2, Expected: 1}, err)
+	assert.Equal(t, "item with id (1) is at version (2), not (1)", err.Error())
+
+	_, err = itemList.DeleteItem(1, IfVersion(3))
+	assert.Equal(t, &VersionMismatchError{ID: 1, Version: 2, Expected: 3}, err)
+	assert.Equal(t, 1, itemList.Count())
+
+	item, err := itemList.UpdateItem(1, "ghi", IfVersion(2))
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 1, Item: "ghi", Version: 3}, item)
+
+	_, err = itemList.DeleteItem(1, IfVersion(3))
+	require.Nil(t, err)
+	assert.Equal(t, 0, itemList.Count())
+}
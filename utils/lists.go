@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultList is the list the original, un-nested routes operate on. It
+// always exists and can't be deleted.
+const DefaultList = "default"
+
+var listNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ListBackend opens the stores behind named lists.
+type ListBackend interface {
+	// Open opens the named list's store, creating it if it doesn't exist.
+	Open(name string) (Store, error)
+	// Remove deletes the named list's data once its store has been closed.
+	Remove(name string) error
+	// Names returns the lists that already exist, apart from the default list.
+	Names() ([]string, error)
+}
+
+type ListInfo struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Lists holds the named to-do lists, each with its own store.
+type Lists struct {
+	backend ListBackend
+	stores  map[string]Store
+	m       sync.RWMutex
+	// undoDepth is applied to lists created later on, unless it is negative.
+	undoDepth int
+}
+
+// NewLists opens every list the backend already has, next to the given
+// default list.
+func NewLists(defaultList Store, backend ListBackend) (*Lists, error) {
+	l := &Lists{
+		backend:   backend,
+		stores:    map[string]Store{DefaultList: defaultList},
+		undoDepth: -1,
+	}
+
+	names, err := backend.Names()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		store, err := backend.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("error opening list (%v): %v", name, err)
+		}
+
+		l.stores[name] = store
+	}
+
+	return l, nil
+}
+
+func ValidateListName(name string) error {
+	if !listNamePattern.MatchString(name) {
+		return NewFieldError(ErrValidation, "name", "list name (%v) has to be 1 to 64 lower-case letters, digits, '-' or '_', starting with a letter or digit", name)
+	}
+
+	return nil
+}
+
+func (l *Lists) Default() Store {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	return l.stores[DefaultList]
+}
+
+func (l *Lists) Get(name string) (Store, error) {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	store, ok := l.stores[name]
+	if !ok {
+		return nil, NewError(ErrNotFound, "list (%v) does not exist", name)
+	}
+
+	return store, nil
+}
+
+func (l *Lists) Create(name string) (ListInfo, error) {
+	err := ValidateListName(name)
+	if err != nil {
+		return ListInfo{}, err
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if _, ok := l.stores[name]; ok {
+		return ListInfo{}, NewError(ErrConflict, "list (%v) already exists", name)
+	}
+
+	store, err := l.backend.Open(name)
+	if err != nil {
+		return ListInfo{}, err
+	}
+
+	if l.undoDepth >= 0 {
+		store.SetUndoDepth(l.undoDepth)
+	}
+	l.stores[name] = store
+
+	return ListInfo{Name: name, Count: store.Count()}, nil
+}
+
+// SetUndoDepth sets the undo depth of every list, including those created
+// later on.
+func (l *Lists) SetUndoDepth(depth int) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.undoDepth = depth
+	for _, store := range l.stores {
+		store.SetUndoDepth(depth)
+	}
+}
+
+// Delete removes the list along with all of its items.
+func (l *Lists) Delete(name string) (ListInfo, error) {
+	if name == DefaultList {
+		return ListInfo{}, NewError(ErrConflict, "the %v list can't be deleted", DefaultList)
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	store, ok := l.stores[name]
+	if !ok {
+		return ListInfo{}, NewError(ErrNotFound, "list (%v) does not exist", name)
+	}
+
+	info := ListInfo{Name: name, Count: store.Count()}
+
+	// requests that got hold of the store before it was closed can't write
+	// its files again once they have been removed
+	err := store.Close()
+	if err != nil {
+		return ListInfo{}, err
+	}
+
+	err = l.backend.Remove(name)
+	if err != nil {
+		return ListInfo{}, err
+	}
+
+	delete(l.stores, name)
+
+	return info, nil
+}
+
+// All returns every list sorted by name.
+func (l *Lists) All() []ListInfo {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	lists := []ListInfo{}
+	for name, store := range l.stores {
+		lists = append(lists, ListInfo{Name: name, Count: store.Count()})
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Name < lists[j].Name
+	})
+
+	return lists
+}
+
+// MemoryLists keeps named lists in memory only.
+type MemoryLists struct{}
+
+func (MemoryLists) Open(name string) (Store, error) {
+	return NewItemList(), nil
+}
+
+func (MemoryLists) Remove(name string) error {
+	return nil
+}
+
+func (MemoryLists) Names() ([]string, error) {
+	return nil, nil
+}
+
+// DirLists keeps every named list at Dir/<name><Ext>, opened with OpenStore.
+type DirLists struct {
+	Dir       string
+	Ext       string
+	OpenStore func(path string) (Store, error)
+}
+
+func (d DirLists) Open(name string) (Store, error) {
+	err := os.MkdirAll(d.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.OpenStore(d.path(name))
+}
+
+func (d DirLists) Remove(name string) error {
+	return os.RemoveAll(d.path(name))
+}
+
+func (d DirLists) Names() ([]string, error) {
+	entries, err := os.ReadDir(d.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), d.Ext)
+		if strings.HasSuffix(entry.Name(), d.Ext) && ValidateListName(name) == nil && name != DefaultList {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+func (d DirLists) path(name string) string {
+	return filepath.Join(d.Dir, name+d.Ext)
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateListName(t *testing.T) {
+	testTable := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "sprint", valid: true},
+		{name: "release-checklist", valid: true},
+		{name: "q4_2022", valid: true},
+		{name: "", valid: false},
+		{name: "Sprint", valid: false},
+		{name: "-sprint", valid: false},
+		{name: "../sprint", valid: false},
+		{name: "my list", valid: false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateListName(testCase.name)
+
+			assert.Equal(t, testCase.valid, err == nil)
+		})
+	}
+}
+
+func TestLists(t *testing.T) {
+	lists, err := NewLists(NewItemList(), MemoryLists{})
+	require.Nil(t, err)
+
+	_, err = lists.Create("sprint")
+	assert.Nil(t, err)
+	_, err = lists.Create("sprint")
+	assert.Equal(t, NewError(ErrConflict, "list (sprint) already exists"), err)
+
+	sprint, err := lists.Get("sprint")
+	require.Nil(t, err)
+	sprint.CreateItem("abc")
+	assert.Equal(t, 0, lists.Default().Count())
+
+	assert.Equal(t, []ListInfo{{Name: "default"}, {Name: "sprint", Count: 1}}, lists.All())
+
+	_, err = lists.Delete(DefaultList)
+	assert.Equal(t, NewError(ErrConflict, "the default list can't be deleted"), err)
+
+	info, err := lists.Delete("sprint")
+	assert.Nil(t, err)
+	assert.Equal(t, ListInfo{Name: "sprint", Count: 1}, info)
+
+	_, err = lists.Get("sprint")
+	assert.Equal(t, NewError(ErrNotFound, "list (sprint) does not exist"), err)
+}
+
+func TestDirLists(t *testing.T) {
+	dir := t.TempDir()
+	backend := DirLists{
+		Dir: filepath.Join(dir, "lists"),
+		Ext: ".db",
+		OpenStore: func(path string) (Store, error) {
+			return NewSQLStore(path)
+		},
+	}
+
+	lists, err := NewLists(NewItemList(), backend)
+	require.Nil(t, err)
+
+	_, err = lists.Create("sprint")
+	require.Nil(t, err)
+	_, err = lists.Create("personal")
+	require.Nil(t, err)
+
+	sprint, _ := lists.Get("sprint")
+	sprint.CreateItem("abc")
+
+	personal, _ := lists.Get("personal")
+	_, err = lists.Delete("personal")
+	require.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "lists", "personal.db"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = personal.CreateItem("abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// existing lists are picked up again after a restart
+	sprint.Close()
+
+	lists, err = NewLists(NewItemList(), backend)
+	require.Nil(t, err)
+	assert.Equal(t, []ListInfo{{Name: "default"}, {Name: "sprint", Count: 1}}, lists.All())
+}
+
+func TestLists_DeleteClosesStore(t *testing.T) {
+	dir := t.TempDir()
+	backend := DirLists{
+		Dir: dir,
+		Ext: ".json",
+		OpenStore: func(path string) (Store, error) {
+			return NewFileStore(path)
+		},
+	}
+
+	lists, err := NewLists(NewItemList(), backend)
+	require.Nil(t, err)
+	_, err = lists.Create("sprint")
+	require.Nil(t, err)
+
+	// a request that got the store before the list was deleted can't bring
+	// its file back
+	sprint, err := lists.Get("sprint")
+	require.Nil(t, err)
+	_, err = lists.Delete("sprint")
+	require.Nil(t, err)
+
+	_, err = sprint.CreateItem("abc")
+	assert.Equal(t, NewError(ErrNotFound, "the list has been closed"), err)
+	_, err = os.Stat(filepath.Join(dir, "sprint.json"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,88 @@
+package utils
+
+import "fmt"
+
+// Placement picks the index the item with the given id is moved to among the
+// other items of the list, see MoveItem.
+type Placement func(others []ItemAndID, id int) (int, error)
+
+// MoveBefore places the item in front of the item with the given id.
+func MoveBefore(id int) Placement {
+	return func(others []ItemAndID, movedID int) (int, error) {
+		return indexOfNeighbour(others, movedID, id)
+	}
+}
+
+// MoveAfter places the item right behind the item with the given id.
+func MoveAfter(id int) Placement {
+	return func(others []ItemAndID, movedID int) (int, error) {
+		index, err := indexOfNeighbour(others, movedID, id)
+		return index + 1, err
+	}
+}
+
+// MoveToPosition places the item at the given position, 1 being the top of
+// the list.
+func MoveToPosition(position int) Placement {
+	return func(others []ItemAndID, movedID int) (int, error) {
+		if position < 1 || position > len(others)+1 {
+			return 0, NewFieldError(ErrValidation, "position", "position (%v) has to be between 1 and %v", position, len(others)+1)
+		}
+
+		return position - 1, nil
+	}
+}
+
+// MoveItem changes the item's place in the list, which is the order ReadAll
+// returns the items in, and keeps it there until it is moved again. The
+// item itself, including its version, stays the same.
+func (il *ItemList) MoveItem(id int, placement Placement) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	item := il.items[index]
+	others := removeItem(copyItems(il.items), id)
+
+	at, err := placement(others, id)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+	if at == index {
+		return item, nil
+	}
+
+	before := 0
+	if at < len(others) {
+		before = others[at].ID
+	}
+
+	err = il.commit(fmt.Sprintf("move item %v", id), moveChange(id, before))
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return item, nil
+}
+
+// indexOfNeighbour returns the index of the item the moved item is placed
+// next to.
+func indexOfNeighbour(others []ItemAndID, movedID int, id int) (int, error) {
+	if id < 1 {
+		return 0, NewError(ErrInvalidID, "id is less than 1")
+	}
+	if id == movedID {
+		return 0, NewError(ErrValidation, "item with id (%v) can't be moved next to itself", id)
+	}
+
+	index := indexOf(others, id)
+	if index < 0 {
+		return 0, NewError(ErrValidation, "item with id (%v) does not exist", id)
+	}
+
+	return index, nil
+}
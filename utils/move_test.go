@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestMoveItem(t *testing.T) {
+	testTable := []struct {
+		name          string
+		id            int
+		placement     Placement
+		expectedIDs   []int
+		expectedError error
+	}{
+		{
+			name:        "before",
+			id:          4,
+			placement:   MoveBefore(2),
+			expectedIDs: []int{1, 4, 2, 3},
+		},
+		{
+			name:        "before the first item",
+			id:          3,
+			placement:   MoveBefore(1),
+			expectedIDs: []int{3, 1, 2, 4},
+		},
+		{
+			name:        "after",
+			id:          1,
+			placement:   MoveAfter(3),
+			expectedIDs: []int{2, 3, 1, 4},
+		},
+		{
+			name:        "after the last item",
+			id:          2,
+			placement:   MoveAfter(4),
+			expectedIDs: []int{1, 3, 4, 2},
+		},
+		{
+			name:        "position",
+			id:          4,
+			placement:   MoveToPosition(1),
+			expectedIDs: []int{4, 1, 2, 3},
+		},
+		{
+			name:        "last position",
+			id:          1,
+			placement:   MoveToPosition(4),
+			expectedIDs: []int{2, 3, 4, 1},
+		},
+		{
+			name:        "same place",
+			id:          2,
+			placement:   MoveAfter(1),
+			expectedIDs: []int{1, 2, 3, 4},
+		},
+		{
+			name:          "position out of range",
+			id:            1,
+			placement:     MoveToPosition(5),
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedError: NewFieldError(ErrValidation, "position", "position (5) has to be between 1 and 4"),
+		},
+		{
+			name:          "next to itself",
+			id:            1,
+			placement:     MoveBefore(1),
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedError: NewError(ErrValidation, "item with id (1) can't be moved next to itself"),
+		},
+		{
+			name:          "nonexistent neighbour",
+			id:            1,
+			placement:     MoveAfter(5),
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedError: NewError(ErrValidation, "item with id (5) does not exist"),
+		},
+		{
+			name:          "nonexistent item",
+			id:            5,
+			placement:     MoveToPosition(1),
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedError: NewError(ErrNotFound, "item with id (5) does not exist"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			itemList := itemListOf("abc", "bcd", "cdf", "def")
+
+			item, err := itemList.MoveItem(testCase.id, testCase.placement)
+
+			assert.Equal(t, testCase.expectedError, err)
+			if err == nil {
+				assert.Equal(t, ItemAndID{ID: testCase.id, Item: item.Item, Version: 1}, item)
+			}
+			assert.Equal(t, testCase.expectedIDs, itemIDs(itemList.ReadAll()))
+		})
+	}
+}
+
+func TestMoveItem_Undo(t *testing.T) {
+	itemList := itemListOf("abc", "bcd", "cdf")
+	_, err := itemList.MoveItem(3, MoveToPosition(1))
+	require.Nil(t, err)
+
+	op, err := itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, Operation{Description: "move item 3", Items: []ItemAndID{{ID: 3, Item: "cdf", Version: 1}}}, op)
+	assert.Equal(t, []int{1, 2, 3}, itemIDs(itemList.ReadAll()))
+
+	_, err = itemList.Redo()
+	require.Nil(t, err)
+	assert.Equal(t, []int{3, 1, 2}, itemIDs(itemList.ReadAll()))
+}
+
+func TestMoveItem_Concurrent(t *testing.T) {
+	itemList := itemListOf("a", "b", "c", "d", "e", "f", "g", "h")
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				itemList.MoveItem(id, MoveToPosition(j%8+1))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// every item is still there exactly once
+	ids := itemIDs(itemList.ReadAll())
+	sort.Ints(ids)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, ids)
+}
+
+func itemIDs(items []ItemAndID) []int {
+	ids := []int{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the number of items on a page without a limit.
+	DefaultPageSize = 50
+	// MaxPageSize caps the limit of a page.
+	MaxPageSize = 500
+)
+
+// Page selects a part of the items a query matches: at most Limit items
+// following the Cursor of the previous page, or following the first Offset
+// items. A Limit of 0 means DefaultPageSize; it is capped at MaxPageSize.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// Size returns the number of items on the page, unless it's the last one.
+func (p Page) Size() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageSize
+	case p.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return p.Limit
+	}
+}
+
+// Validate checks that the limit and offset aren't negative and that the
+// page isn't given by both a cursor and an offset.
+func (p Page) Validate() error {
+	if p.Limit < 0 {
+		return NewFieldError(ErrValidation, "limit", "limit (%v) has to be at least 0", p.Limit)
+	}
+
+	if p.Offset < 0 {
+		return NewFieldError(ErrValidation, "offset", "offset (%v) has to be at least 0", p.Offset)
+	}
+	if p.Offset > 0 && p.Cursor != "" {
+		return NewFieldError(ErrValidation, "cursor", "cursor and offset can't be combined")
+	}
+
+	return nil
+}
+
+// ItemPage is a page of items. NextCursor selects the page after it and is
+// empty on the last page.
+type ItemPage struct {
+	Items      []ItemAndID `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// cursor marks the end of a page by its last item rather than by position,
+// so that items inserted or deleted in front of it don't shift the next
+// page. In a sorted list that is the item's ID and the values it is sorted
+// by. In the list's own order Next, the item following the page, and
+// Offset, where the next page started, stand in for the last item once it
+// is gone.
+type cursor struct {
+	Sort        SortOrder  `json:"s,omitempty"`
+	After       int        `json:"a"`
+	Next        int        `json:"n,omitempty"`
+	Offset      int        `json:"o"`
+	Item        string     `json:"i,omitempty"`
+	Done        bool       `json:"x,omitempty"`
+	Due         *time.Time `json:"d,omitempty"`
+	Priority    int        `json:"p,omitempty"`
+	CompletedAt *time.Time `json:"c,omitempty"`
+}
+
+func newCursor(order SortOrder, last ItemAndID, next ItemAndID, offset int) cursor {
+	c := cursor{Sort: order, After: last.ID, Next: next.ID, Offset: offset}
+	for _, key := range order.keys() {
+		switch key.field {
+		case "item":
+			c.Item = last.Item
+		case "done":
+			c.Done = last.Done
+		case "due":
+			c.Due = last.Due
+		case "priority":
+			c.Priority = int(last.Priority)
+		case "completed_at":
+			c.CompletedAt = last.CompletedAt
+		}
+	}
+
+	return c
+}
+
+// last returns the sort values of the page's last item.
+func (c cursor) last() ItemAndID {
+	return ItemAndID{ID: c.After, Item: c.Item, Done: c.Done, Due: c.Due, Priority: Priority(c.Priority), CompletedAt: c.CompletedAt}
+}
+
+// Paginate returns the page of the items, which have to be the result of
+// Find with the query.
+func (q Query) Paginate(items []ItemAndID, page Page) (ItemPage, error) {
+	err := page.Validate()
+	if err != nil {
+		return ItemPage{}, err
+	}
+
+	start := page.Offset
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil || c.Sort != q.Sort {
+			return ItemPage{}, NewFieldError(ErrValidation, "cursor", "cursor (%v) is invalid for this query", page.Cursor)
+		}
+
+		start = q.startOf(items, c)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + page.Size()
+	if end > len(items) {
+		end = len(items)
+	}
+
+	result := ItemPage{Items: items[start:end]}
+	if end < len(items) {
+		result.NextCursor = encodeCursor(newCursor(q.Sort, items[end-1], items[end], end))
+	}
+
+	return result, nil
+}
+
+// startOf returns the index of the first item after the cursor.
+func (q Query) startOf(items []ItemAndID, c cursor) int {
+	if q.Sort != SortList {
+		// the sort order is a total order, which places the last item
+		// wherever it is now
+		last := c.last()
+		for i, item := range items {
+			if q.Sort.compare(item, last) > 0 {
+				return i
+			}
+		}
+
+		return len(items)
+	}
+
+	if index := indexOf(items, c.After); index >= 0 {
+		return index + 1
+	}
+	if index := indexOf(items, c.Next); index >= 0 {
+		return index
+	}
+
+	return c.Offset
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, err
+	}
+
+	var c cursor
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return cursor{}, err
+	}
+
+	// cursors come back from clients, who may have made this one up
+	if c.Offset < 0 {
+		return cursor{}, fmt.Errorf("offset (%v) is negative", c.Offset)
+	}
+
+	return c, nil
+}
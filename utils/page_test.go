@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	testTable := []struct {
+		name          string
+		page          Page
+		expectedIDs   []int
+		expectedNext  bool
+		expectedError error
+	}{
+		{
+			name:         "first page",
+			page:         Page{Limit: 2},
+			expectedIDs:  []int{1, 2},
+			expectedNext: true,
+		},
+		{
+			name:        "default limit",
+			page:        Page{},
+			expectedIDs: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:        "zero limit",
+			page:        Page{Limit: 0, Offset: 1},
+			expectedIDs: []int{2, 3, 4, 5},
+		},
+		{
+			name:         "offset",
+			page:         Page{Limit: 2, Offset: 2},
+			expectedIDs:  []int{3, 4},
+			expectedNext: true,
+		},
+		{
+			name:        "last page",
+			page:        Page{Limit: 2, Offset: 4},
+			expectedIDs: []int{5},
+		},
+		{
+			name:        "offset past the end",
+			page:        Page{Limit: 2, Offset: 9},
+			expectedIDs: []int{},
+		},
+		{
+			name:          "negative limit",
+			page:          Page{Limit: -1},
+			expectedError: NewFieldError(ErrValidation, "limit", "limit (-1) has to be at least 0"),
+		},
+		{
+			name:          "negative offset",
+			page:          Page{Offset: -1},
+			expectedError: NewFieldError(ErrValidation, "offset", "offset (-1) has to be at least 0"),
+		},
+		{
+			name:          "cursor and offset",
+			page:          Page{Offset: 1, Cursor: encodeCursor(cursor{After: 1})},
+			expectedError: NewFieldError(ErrValidation, "cursor", "cursor and offset can't be combined"),
+		},
+		{
+			name:          "malformed cursor",
+			page:          Page{Cursor: "abc!"},
+			expectedError: NewFieldError(ErrValidation, "cursor", "cursor (abc!) is invalid for this query"),
+		},
+		{
+			name:          "cursor with a negative offset",
+			page:          Page{Cursor: encodeCursor(cursor{After: 9, Offset: -5})},
+			expectedError: NewFieldError(ErrValidation, "cursor", "cursor (%v) is invalid for this query", encodeCursor(cursor{After: 9, Offset: -5})),
+		},
+		{
+			name:          "cursor of another sort order",
+			page:          Page{Cursor: encodeCursor(cursor{Sort: SortPriority, After: 1})},
+			expectedError: NewFieldError(ErrValidation, "cursor", "cursor (%v) is invalid for this query", encodeCursor(cursor{Sort: SortPriority, After: 1})),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			il := itemListOf("a", "b", "c", "d", "e")
+
+			page, err := Query{}.Paginate(il.Find(Query{}), testCase.page)
+
+			assert.Equal(t, testCase.expectedError, err)
+			if testCase.expectedError == nil {
+				assert.Equal(t, testCase.expectedIDs, itemIDs(page.Items))
+				assert.Equal(t, testCase.expectedNext, page.NextCursor != "")
+			}
+		})
+	}
+}
+
+func TestPaginate_MaxPageSize(t *testing.T) {
+	il := NewItemList()
+	for i := 0; i < MaxPageSize+1; i++ {
+		il.CreateItem("item")
+	}
+
+	page, err := Query{}.Paginate(il.Find(Query{}), Page{Limit: MaxPageSize + 1})
+	require.Nil(t, err)
+	assert.Len(t, page.Items, MaxPageSize)
+	assert.NotEmpty(t, page.NextCursor)
+}
+
+func TestPaginate_CursorSurvivesChanges(t *testing.T) {
+	testTable := []struct {
+		name        string
+		change      func(il *ItemList)
+		expectedIDs []int
+	}{
+		{
+			name:        "no change",
+			change:      func(il *ItemList) {},
+			expectedIDs: []int{3, 4},
+		},
+		{
+			name: "insert in front of the page",
+			change: func(il *ItemList) {
+				il.CreateItem("f")
+				il.MoveItem(6, MoveToPosition(1))
+			},
+			expectedIDs: []int{3, 4},
+		},
+		{
+			name: "insert after the page",
+			change: func(il *ItemList) {
+				il.CreateItem("f")
+				il.MoveItem(6, MoveAfter(2))
+			},
+			expectedIDs: []int{6, 3},
+		},
+		{
+			name:        "delete in front of the page",
+			change:      func(il *ItemList) { il.DeleteItem(1) },
+			expectedIDs: []int{3, 4},
+		},
+		{
+			name:        "delete the last item of the page",
+			change:      func(il *ItemList) { il.DeleteItem(2) },
+			expectedIDs: []int{3, 4},
+		},
+		{
+			name:        "delete the first item of the next page",
+			change:      func(il *ItemList) { il.DeleteItem(3) },
+			expectedIDs: []int{4, 5},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			il := itemListOf("a", "b", "c", "d", "e")
+
+			first, err := Query{}.Paginate(il.Find(Query{}), Page{Limit: 2})
+			require.Nil(t, err)
+			require.Equal(t, []int{1, 2}, itemIDs(first.Items))
+
+			testCase.change(il)
+
+			next, err := Query{}.Paginate(il.Find(Query{}), Page{Limit: 2, Cursor: first.NextCursor})
+			require.Nil(t, err)
+			assert.Equal(t, testCase.expectedIDs, itemIDs(next.Items))
+		})
+	}
+}
+
+func TestPaginate_PrioritySort(t *testing.T) {
+	il := NewItemList()
+	il.CreateItem("a", WithPriority(PriorityLow))
+	il.CreateItem("b", WithPriority(PriorityHigh))
+	il.CreateItem("c")
+	il.CreateItem("d", WithPriority(PriorityHigh))
+	il.CreateItem("e", WithPriority(PriorityLow))
+
+	q := Query{Sort: SortPriority}
+	first, err := q.Paginate(il.Find(q), Page{Limit: 2})
+	require.Nil(t, err)
+	require.Equal(t, []int{2, 4}, itemIDs(first.Items))
+
+	// the page ends at the last item's priority and ID, wherever it went
+	il.UpdateItem(4, "d", WithPriority(PriorityLow))
+	il.CreateItem("f", WithPriority(PriorityUrgent))
+	il.CreateItem("g", WithPriority(PriorityLow))
+
+	next, err := q.Paginate(il.Find(q), Page{Limit: 3, Cursor: first.NextCursor})
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 4, 5}, itemIDs(next.Items))
+
+	last, err := q.Paginate(il.Find(q), Page{Limit: 3, Cursor: next.NextCursor})
+	require.Nil(t, err)
+	assert.Equal(t, []int{7, 3}, itemIDs(last.Items))
+	assert.Empty(t, last.NextCursor)
+}
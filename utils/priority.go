@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Priority orders items for triage; higher values are more pressing. Items
+// created without a priority have PriorityNone.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// Priorities lists the priorities from most to least pressing.
+var Priorities = []Priority{PriorityUrgent, PriorityHigh, PriorityMedium, PriorityLow, PriorityNone}
+
+// ParsePriority accepts a priority's name or its number, 1 (low) to 4 (urgent).
+// "none" or 0 stand for no priority.
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	for i, name := range priorityNames {
+		if s == name || s == strconv.Itoa(i) {
+			return Priority(i), nil
+		}
+	}
+
+	return PriorityNone, NewFieldError(ErrValidation, "priority", "priority (%v) has to be one of low, medium, high, urgent or 1 to 4", s)
+}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return strconv.Itoa(int(p))
+	}
+
+	return priorityNames[p]
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) != nil {
+		s = string(b)
+	}
+
+	priority, err := ParsePriority(s)
+	if err != nil {
+		return err
+	}
+
+	*p = priority
+
+	return nil
+}
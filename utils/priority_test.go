@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePriority(t *testing.T) {
+	testTable := []struct {
+		name             string
+		value            string
+		expectedPriority Priority
+		expectedError    error
+	}{
+		{
+			name:             "name",
+			value:            "urgent",
+			expectedPriority: PriorityUrgent,
+		},
+		{
+			name:             "name with different case and whitespace",
+			value:            " Medium ",
+			expectedPriority: PriorityMedium,
+		},
+		{
+			name:             "number",
+			value:            "1",
+			expectedPriority: PriorityLow,
+		},
+		{
+			name:             "none",
+			value:            "none",
+			expectedPriority: PriorityNone,
+		},
+		{
+			name:          "unknown name",
+			value:         "critical",
+			expectedError: NewFieldError(ErrValidation, "priority", "priority (critical) has to be one of low, medium, high, urgent or 1 to 4"),
+		},
+		{
+			name:          "number out of range",
+			value:         "5",
+			expectedError: NewFieldError(ErrValidation, "priority", "priority (5) has to be one of low, medium, high, urgent or 1 to 4"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			priority, err := ParsePriority(testCase.value)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedPriority, priority)
+		})
+	}
+}
+
+func TestPriorityJSON(t *testing.T) {
+	b, err := json.Marshal(ItemAndID{ID: 1, Item: "abc", Priority: PriorityHigh})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":1,"item":"abc","done":false,"priority":"high"}`, string(b))
+
+	b, err = json.Marshal(ItemAndID{ID: 1, Item: "abc"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":1,"item":"abc","done":false}`, string(b))
+
+	var item ItemAndID
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":1,"item":"abc","priority":2}`), &item))
+	assert.Equal(t, PriorityMedium, item.Priority)
+}
+
+func TestFind_SortByPriority(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("none")
+	itemList.CreateItem("low", WithPriority(PriorityLow))
+	itemList.CreateItem("urgent", WithPriority(PriorityUrgent))
+	itemList.CreateItem("another low", WithPriority(PriorityLow))
+
+	ids := []int{}
+	for _, item := range itemList.Find(Query{Sort: SortPriority}) {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{3, 2, 4, 1}, ids)
+
+	// sorting doesn't change the list itself
+	ids = []int{}
+	for _, item := range itemList.ReadAll() {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+}
@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusAll  Status = "all"
+	StatusOpen Status = "open"
+	StatusDone Status = "done"
+)
+
+func ParseStatus(s string) (Status, error) {
+	switch status := Status(s); status {
+	case StatusAll, StatusOpen, StatusDone:
+		return status, nil
+	case "":
+		return StatusAll, nil
+	default:
+		return "", NewFieldError(ErrValidation, "status", "status (%v) has to be one of %v, %v or %v", s, StatusOpen, StatusDone, StatusAll)
+	}
+}
+
+// SortOrder is a comma-separated list of the fields to sort by, each
+// prefixed with "-" to reverse it. Items equal in all of them are ordered by
+// creation.
+type SortOrder string
+
+const (
+	// SortList keeps the order of the list.
+	SortList SortOrder = ""
+	// SortPriority puts the most pressing items first and orders items of the
+	// same priority by creation.
+	SortPriority SortOrder = "priority"
+)
+
+type sortField struct {
+	// compare compares two items by the field in its natural order: pressing
+	// and early items first.
+	compare func(a, b ItemAndID) int
+	// missing, if set, reports whether the item has no value for the field;
+	// those items are last in either direction.
+	missing func(item ItemAndID) bool
+}
+
+var sortFields = map[string]sortField{
+	"id": {compare: func(a, b ItemAndID) int { return a.ID - b.ID }},
+	"item": {compare: func(a, b ItemAndID) int {
+		return strings.Compare(strings.ToLower(a.Item), strings.ToLower(b.Item))
+	}},
+	"done": {compare: func(a, b ItemAndID) int { return boolToInt(a.Done) - boolToInt(b.Done) }},
+	"due": {
+		compare: func(a, b ItemAndID) int { return compareTimes(*a.Due, *b.Due) },
+		missing: func(item ItemAndID) bool { return item.Due == nil },
+	},
+	"priority": {compare: func(a, b ItemAndID) int { return int(b.Priority) - int(a.Priority) }},
+	"completed_at": {
+		compare: func(a, b ItemAndID) int { return compareTimes(*a.CompletedAt, *b.CompletedAt) },
+		missing: func(item ItemAndID) bool { return item.CompletedAt == nil },
+	},
+}
+
+type sortKey struct {
+	field   string
+	reverse bool
+}
+
+func ParseSortOrder(s string) (SortOrder, error) {
+	if s == "" {
+		return SortList, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := sortFields[strings.TrimPrefix(field, "-")]; !ok {
+			return "", NewFieldError(ErrValidation, "sort", "sort (%v) has to be a comma-separated list of id, item, done, due, priority or completed_at, each optionally prefixed with -", s)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return SortOrder(strings.Join(fields, ",")), nil
+}
+
+func (order SortOrder) keys() []sortKey {
+	if order == SortList {
+		return nil
+	}
+
+	var keys []sortKey
+	for _, field := range strings.Split(string(order), ",") {
+		keys = append(keys, sortKey{field: strings.TrimPrefix(field, "-"), reverse: strings.HasPrefix(field, "-")})
+	}
+
+	return keys
+}
+
+// compare compares the items by the sort order, falling back to their IDs,
+// which are handed out in creation order.
+func (order SortOrder) compare(a, b ItemAndID) int {
+	for _, key := range order.keys() {
+		field := sortFields[key.field]
+		if field.missing != nil {
+			aMissing, bMissing := field.missing(a), field.missing(b)
+			if aMissing != bMissing {
+				return boolToInt(aMissing) - boolToInt(bMissing)
+			}
+			if aMissing {
+				continue
+			}
+		}
+
+		c := field.compare(a, b)
+		if key.reverse {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return a.ID - b.ID
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+type TagMode string
+
+const (
+	// TagsAll matches items carrying every tag of the query.
+	TagsAll TagMode = "and"
+	// TagsAny matches items carrying at least one tag of the query.
+	TagsAny TagMode = "or"
+)
+
+func ParseTagMode(s string) (TagMode, error) {
+	switch mode := TagMode(s); mode {
+	case TagsAll, TagsAny:
+		return mode, nil
+	case "":
+		return TagsAll, nil
+	default:
+		return "", NewFieldError(ErrValidation, "tag_mode", "tag mode (%v) has to be %v or %v", s, TagsAll, TagsAny)
+	}
+}
+
+// Query selects items for Store.Find. The zero value matches every item.
+type Query struct {
+	Status Status
+	// HasDue only matches items with a due date. It is implied by DueBefore
+	// and DueAfter, which match due dates strictly before or after the time.
+	HasDue    bool
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Tags have to be normalized; TagMode defaults to TagsAll.
+	Tags    []string
+	TagMode TagMode
+	// AsOf matches the items as they were at the time, reconstructed from
+	// their history, rather than the current ones.
+	AsOf *time.Time
+	// Filter, if set, has to match the items as well; see ParseFilter.
+	Filter Filter
+
+	Sort SortOrder
+}
+
+// find returns the items matching the query in the query's sort order.
+func (q Query) find(source []ItemAndID) []ItemAndID {
+	items := []ItemAndID{}
+	for _, item := range source {
+		if q.matches(item) {
+			items = append(items, item)
+		}
+	}
+
+	q.sort(items)
+
+	return items
+}
+
+func (q Query) matches(item ItemAndID) bool {
+	switch q.Status {
+	case StatusOpen:
+		if item.Done {
+			return false
+		}
+	case StatusDone:
+		if !item.Done {
+			return false
+		}
+	}
+
+	if q.HasDue || q.DueBefore != nil || q.DueAfter != nil {
+		if item.Due == nil {
+			return false
+		}
+		if q.DueBefore != nil && !item.Due.Before(*q.DueBefore) {
+			return false
+		}
+		if q.DueAfter != nil && !item.Due.After(*q.DueAfter) {
+			return false
+		}
+	}
+
+	if len(q.Tags) > 0 && !q.matchesTags(item) {
+		return false
+	}
+
+	if q.Filter != nil && !q.Filter.Match(item) {
+		return false
+	}
+
+	return true
+}
+
+func (q Query) matchesTags(item ItemAndID) bool {
+	for _, tag := range q.Tags {
+		found := false
+		for _, t := range item.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+
+		if found && q.TagMode == TagsAny {
+			return true
+		}
+		if !found && q.TagMode != TagsAny {
+			return false
+		}
+	}
+
+	return q.TagMode != TagsAny
+}
+
+func (q Query) sort(items []ItemAndID) {
+	if q.Sort == SortList {
+		return
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return q.Sort.compare(items[i], items[j]) < 0
+	})
+}
@@ -0,0 +1,345 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is a parsed recurrence rule: daily, weekly, monthly, yearly or a
+// five field cron expression ("minute hour day-of-month month day-of-week").
+// All times are evaluated in UTC, like due dates.
+type Recurrence struct {
+	rule string
+	// months and days are the interval of the named rules
+	months int
+	days   int
+	cron   *cronSchedule
+}
+
+var recurrenceIntervals = map[string]Recurrence{
+	"daily":   {rule: "daily", days: 1},
+	"weekly":  {rule: "weekly", days: 7},
+	"monthly": {rule: "monthly", months: 1},
+	"yearly":  {rule: "yearly", months: 12},
+}
+
+// cronSearchYears bounds the search for the next time a cron expression
+// matches; eight years always include a 29th of February.
+const cronSearchYears = 8
+
+// ParseRecurrence parses a recurrence rule; the empty rule stands for no
+// recurrence.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	fields := strings.Fields(strings.ToLower(rule))
+
+	switch len(fields) {
+	case 0:
+		return Recurrence{}, nil
+	case 1:
+		r, ok := recurrenceIntervals[fields[0]]
+		if !ok {
+			return Recurrence{}, NewFieldError(ErrValidation, "recurrence", "recurrence (%v) has to be one of daily, weekly, monthly, yearly or a cron expression", rule)
+		}
+
+		return r, nil
+	case 5:
+		cron, err := parseCron(fields)
+		if err != nil {
+			return Recurrence{}, NewFieldError(ErrValidation, "recurrence", "recurrence (%v) %v", rule, err)
+		}
+
+		// catch expressions like "0 0 30 2 *" that never match
+		reference := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if _, ok := cron.next(reference); !ok {
+			return Recurrence{}, NewFieldError(ErrValidation, "recurrence", "recurrence (%v) never occurs", rule)
+		}
+
+		return Recurrence{rule: strings.Join(fields, " "), cron: cron}, nil
+	default:
+		return Recurrence{}, NewFieldError(ErrValidation, "recurrence", "recurrence (%v) has to be one of daily, weekly, monthly, yearly or a cron expression", rule)
+	}
+}
+
+// String returns the normalized rule.
+func (r Recurrence) String() string {
+	return r.rule
+}
+
+func (r Recurrence) IsZero() bool {
+	return r.rule == ""
+}
+
+// Next returns the first occurrence after the given time. The named rules
+// count their intervals from start, if set, so that a monthly series due on
+// the 31st falls on the last day of shorter months without drifting to an
+// earlier day afterwards. Without a start the interval is added to after.
+func (r Recurrence) Next(start *time.Time, after time.Time) (time.Time, bool) {
+	after = after.UTC()
+
+	switch {
+	case r.cron != nil:
+		return r.cron.next(after)
+	case r.IsZero():
+		return time.Time{}, false
+	case start == nil:
+		return r.occurrence(after, 1), true
+	}
+
+	s := start.UTC()
+
+	// estimate the number of intervals and correct the estimate, which can be
+	// off by one because months have different lengths
+	n := 0
+	if after.After(s) {
+		if r.months > 0 {
+			n = ((after.Year()-s.Year())*12 + int(after.Month()-s.Month())) / r.months
+		} else {
+			n = int(after.Sub(s).Hours()/24) / r.days
+		}
+	}
+	for n > 0 && r.occurrence(s, n).After(after) {
+		n--
+	}
+	for !r.occurrence(s, n).After(after) {
+		n++
+	}
+
+	return r.occurrence(s, n), true
+}
+
+// occurrence returns the nth occurrence counted from start. Months keep the
+// day of start, or use the last day of the month if it is shorter.
+func (r Recurrence) occurrence(start time.Time, n int) time.Time {
+	if r.months == 0 {
+		return start.AddDate(0, 0, n*r.days)
+	}
+
+	first := time.Date(start.Year(), start.Month()+time.Month(n*r.months), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.UTC)
+
+	day := start.Day()
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// WithRecurrence makes the item recur; the zero Recurrence stops it. The
+// series is counted from the item's due date.
+func WithRecurrence(r Recurrence) ItemOption {
+	return func(item *ItemAndID) {
+		item.Recurrence = r.String()
+		item.RecurrenceStart = nil
+	}
+}
+
+// NextOccurrence returns the due date of the occurrence that completing the
+// item at the given time would create, or nil if the item doesn't recur.
+// Occurrences that are already past at that time are skipped.
+func (item ItemAndID) NextOccurrence(at time.Time) *time.Time {
+	if item.Recurrence == "" {
+		return nil
+	}
+
+	r, err := ParseRecurrence(item.Recurrence)
+	if err != nil {
+		return nil
+	}
+
+	after := at
+	if item.Due != nil && item.Due.After(after) {
+		after = *item.Due
+	}
+
+	next, ok := r.Next(item.RecurrenceStart, after)
+	if !ok {
+		return nil
+	}
+
+	return &next
+}
+
+// anchorRecurrence starts the item's series at its due date unless it has
+// started already.
+func (item *ItemAndID) anchorRecurrence() {
+	if item.Recurrence == "" {
+		item.RecurrenceStart = nil
+		return
+	}
+
+	if item.RecurrenceStart == nil && item.Due != nil {
+		start := *item.Due
+		item.RecurrenceStart = &start
+	}
+}
+
+// nextOccurrence returns a copy of the completed item, without its ID, due
+// at the next occurrence. The copy keeps the item's text, notes, priority,
+// tags, parent and series but none of its blockers.
+func (item ItemAndID) nextOccurrence(completedAt time.Time) (ItemAndID, bool) {
+	due := item.NextOccurrence(completedAt)
+	if due == nil {
+		return ItemAndID{}, false
+	}
+
+	start := item.RecurrenceStart
+	if start == nil {
+		start = due
+	}
+
+	return ItemAndID{
+		Item:            item.Item,
+		Notes:           item.Notes,
+		Due:             due,
+		Priority:        item.Priority,
+		Tags:            item.Tags,
+		ParentID:        item.ParentID,
+		Recurrence:      item.Recurrence,
+		RecurrenceStart: start,
+	}, true
+}
+
+// cronSchedule holds the values each field of a cron expression matches.
+type cronSchedule struct {
+	minutes [60]bool
+	hours   [24]bool
+	// days of the month are 1 based, days of the week start at Sunday
+	doms   [32]bool
+	months [13]bool
+	dows   [7]bool
+	// like in cron, when both day fields are restricted a day matching either
+	// of them matches; a field starting with "*", even with a step, counts as
+	// unrestricted
+	domAny bool
+	dowAny bool
+}
+
+var cronFieldNames = []string{"minute", "hour", "day of month", "month", "day of week"}
+
+func parseCron(fields []string) (*cronSchedule, error) {
+	var c cronSchedule
+	bounds := [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+	for i, field := range fields {
+		values, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("has an invalid %v field (%v): %v", cronFieldNames[i], field, err)
+		}
+
+		for _, v := range values {
+			switch i {
+			case 0:
+				c.minutes[v] = true
+			case 1:
+				c.hours[v] = true
+			case 2:
+				c.doms[v] = true
+			case 3:
+				c.months[v] = true
+			case 4:
+				// 7 is Sunday as well
+				c.dows[v%7] = true
+			}
+		}
+	}
+
+	c.domAny = strings.HasPrefix(fields[2], "*")
+	c.dowAny = strings.HasPrefix(fields[4], "*")
+
+	return &c, nil
+}
+
+// parseCronField parses a comma separated list of "*", "n" or "a-b", each
+// optionally followed by a step "/s".
+func parseCronField(field string, min int, max int) ([]int, error) {
+	var values []int
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("step (%v) is not a positive number", part[i+1:])
+			}
+			rangePart, step = part[:i], s
+		}
+
+		from, to := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("(%v) is not a number", bounds[0])
+			}
+
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("(%v) is not a number", bounds[1])
+				}
+			} else if step > 1 {
+				// "a/s" runs from a to the end of the range
+				to = max
+			}
+
+			if from < min || to > max || from > to {
+				return nil, fmt.Errorf("(%v) is not within %v-%v", rangePart, min, max)
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			values = append(values, v)
+		}
+	}
+
+	return values, nil
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.doms[t.Day()]
+	dow := c.dows[t.Weekday()]
+
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// next returns the first minute after the given time that matches, skipping
+// whole months, days and hours that don't.
+func (c *cronSchedule) next(after time.Time) (time.Time, bool) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !c.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !c.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	testTable := []struct {
+		name          string
+		rule          string
+		expected      string
+		expectedError error
+	}{
+		{name: "none", rule: "", expected: ""},
+		{name: "named", rule: " Weekly", expected: "weekly"},
+		{name: "cron", rule: "0  9 * *  1-5", expected: "0 9 * * 1-5"},
+		{name: "cron with steps and lists", rule: "*/15 8,12 1 */3 *", expected: "*/15 8,12 1 */3 *"},
+		{
+			name:          "unknown name",
+			rule:          "fortnightly",
+			expectedError: NewFieldError(ErrValidation, "recurrence", "recurrence (fortnightly) has to be one of daily, weekly, monthly, yearly or a cron expression"),
+		},
+		{
+			name:          "wrong number of fields",
+			rule:          "0 9 * *",
+			expectedError: NewFieldError(ErrValidation, "recurrence", "recurrence (0 9 * *) has to be one of daily, weekly, monthly, yearly or a cron expression"),
+		},
+		{
+			name:          "out of range",
+			rule:          "0 24 * * *",
+			expectedError: NewFieldError(ErrValidation, "recurrence", "recurrence (0 24 * * *) has an invalid hour field (24): (24) is not within 0-23"),
+		},
+		{
+			name:          "invalid step",
+			rule:          "*/0 * * * *",
+			expectedError: NewFieldError(ErrValidation, "recurrence", "recurrence (*/0 * * * *) has an invalid minute field (*/0): step (0) is not a positive number"),
+		},
+		{
+			name:          "never occurs",
+			rule:          "0 0 30 2 *",
+			expectedError: NewFieldError(ErrValidation, "recurrence", "recurrence (0 0 30 2 *) never occurs"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := ParseRecurrence(testCase.rule)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expected, r.String())
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	date := func(year int, month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	testTable := []struct {
+		name     string
+		rule     string
+		start    *time.Time
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "daily without start",
+			rule:     "daily",
+			after:    date(2022, 12, 31, 18, 0),
+			expected: date(2023, 1, 1, 18, 0),
+		},
+		{
+			name:     "weekly skips past occurrences",
+			rule:     "weekly",
+			start:    ptr(date(2022, 11, 7, 9, 0)),
+			after:    date(2022, 11, 23, 12, 0),
+			expected: date(2022, 11, 28, 9, 0),
+		},
+		{
+			name:     "weekly on an occurrence",
+			rule:     "weekly",
+			start:    ptr(date(2022, 11, 7, 9, 0)),
+			after:    date(2022, 11, 14, 9, 0),
+			expected: date(2022, 11, 21, 9, 0),
+		},
+		{
+			name:     "monthly from the 31st into February",
+			rule:     "monthly",
+			start:    ptr(date(2023, 1, 31, 9, 0)),
+			after:    date(2023, 1, 31, 9, 0),
+			expected: date(2023, 2, 28, 9, 0),
+		},
+		{
+			name:     "monthly from the 31st into a leap February",
+			rule:     "monthly",
+			start:    ptr(date(2024, 1, 31, 9, 0)),
+			after:    date(2024, 1, 31, 9, 0),
+			expected: date(2024, 2, 29, 9, 0),
+		},
+		{
+			name:     "monthly returns to the 31st after February",
+			rule:     "monthly",
+			start:    ptr(date(2023, 1, 31, 9, 0)),
+			after:    date(2023, 2, 28, 9, 0),
+			expected: date(2023, 3, 31, 9, 0),
+		},
+		{
+			name:     "monthly from the 31st into a 30 day month",
+			rule:     "monthly",
+			start:    ptr(date(2023, 1, 31, 9, 0)),
+			after:    date(2023, 3, 31, 9, 0),
+			expected: date(2023, 4, 30, 9, 0),
+		},
+		{
+			name:     "monthly across the year",
+			rule:     "monthly",
+			start:    ptr(date(2022, 10, 31, 9, 0)),
+			after:    date(2022, 12, 31, 9, 0),
+			expected: date(2023, 1, 31, 9, 0),
+		},
+		{
+			name:     "yearly from the 29th of February",
+			rule:     "yearly",
+			start:    ptr(date(2024, 2, 29, 9, 0)),
+			after:    date(2024, 2, 29, 9, 0),
+			expected: date(2025, 2, 28, 9, 0),
+		},
+		{
+			name:     "yearly back to the 29th of February in a leap year",
+			rule:     "yearly",
+			start:    ptr(date(2024, 2, 29, 9, 0)),
+			after:    date(2027, 2, 28, 9, 0),
+			expected: date(2028, 2, 29, 9, 0),
+		},
+		{
+			name:     "cron on weekdays",
+			rule:     "30 9 * * 1-5",
+			after:    date(2022, 11, 4, 10, 0),
+			expected: date(2022, 11, 7, 9, 30),
+		},
+		{
+			name:     "cron later the same day",
+			rule:     "0 */6 * * *",
+			after:    date(2022, 11, 4, 6, 0),
+			expected: date(2022, 11, 4, 12, 0),
+		},
+		{
+			name:     "cron on the 29th of February",
+			rule:     "0 0 29 2 *",
+			after:    date(2024, 3, 1, 0, 0),
+			expected: date(2028, 2, 29, 0, 0),
+		},
+		{
+			name:     "cron on the 31st skips shorter months",
+			rule:     "0 0 31 * *",
+			after:    date(2023, 3, 31, 0, 0),
+			expected: date(2023, 5, 31, 0, 0),
+		},
+		{
+			name:     "cron matches either day field",
+			rule:     "0 0 13 * 5",
+			after:    date(2022, 11, 1, 0, 0),
+			expected: date(2022, 11, 4, 0, 0),
+		},
+		{
+			name:     "cron with a step in the day of month only matches the day of week",
+			rule:     "0 0 */2 * 1",
+			after:    date(2022, 11, 1, 0, 0),
+			expected: date(2022, 11, 7, 0, 0),
+		},
+		{
+			name:     "cron with Sunday as 7",
+			rule:     "0 0 * * 7",
+			after:    date(2022, 11, 1, 0, 0),
+			expected: date(2022, 11, 6, 0, 0),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := ParseRecurrence(testCase.rule)
+			require.Nil(t, err)
+
+			next, ok := r.Next(testCase.start, testCase.after)
+			assert.True(t, ok)
+			assert.Equal(t, testCase.expected, next)
+		})
+	}
+}
+
+func TestCompleteItem_Recurrence(t *testing.T) {
+	due := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	monthly, err := ParseRecurrence("monthly")
+	require.Nil(t, err)
+
+	itemList := NewItemList()
+	itemList.CreateItem("renew certs", WithDue(due), WithRecurrence(monthly), WithPriority(PriorityHigh))
+	itemList.CreateItem("other")
+
+	// completed before the due date, so the next occurrence follows it
+	completedAt := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return completedAt }
+	defer func() { now = time.Now }()
+
+	completed, err := itemList.CompleteItem(1)
+	require.Nil(t, err)
+
+	february := time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC)
+	next, err := itemList.ReadItem(3)
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{
+		ID:              3,
+		Item:            "renew certs",
+		Version:         1,
+		Due:             &february,
+		Priority:        PriorityHigh,
+		Recurrence:      "monthly",
+		RecurrenceStart: completed.RecurrenceStart,
+	}, next)
+
+	// completed late, so the occurrences that have passed are skipped
+	completedAt = time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+	_, err = itemList.CompleteItem(3)
+	require.Nil(t, err)
+
+	next, err = itemList.ReadItem(4)
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC), *next.Due)
+
+	// completing it again doesn't create another occurrence
+	_, err = itemList.CompleteItem(3)
+	require.Nil(t, err)
+	assert.Equal(t, 4, itemList.Count())
+}
+
+func TestUpdateItem_RecurrenceStart(t *testing.T) {
+	due := time.Date(2023, 1, 31, 9, 0, 0, 0, time.UTC)
+	monthly, err := ParseRecurrence("monthly")
+	require.Nil(t, err)
+
+	itemList := NewItemList()
+	item, err := itemList.CreateItem("pay rent", WithDue(due), WithRecurrence(monthly))
+	require.Nil(t, err)
+	assert.Equal(t, &due, item.RecurrenceStart)
+
+	// changing the text keeps the series, changing the due date restarts it
+	item, err = itemList.UpdateItem(1, "pay the rent")
+	require.Nil(t, err)
+	assert.Equal(t, &due, item.RecurrenceStart)
+
+	newDue := time.Date(2023, 2, 1, 9, 0, 0, 0, time.UTC)
+	item, err = itemList.UpdateItem(1, "pay the rent", WithDue(newDue))
+	require.Nil(t, err)
+	assert.Equal(t, &newDue, item.RecurrenceStart)
+
+	item, err = itemList.UpdateItem(1, "pay the rent", WithRecurrence(Recurrence{}))
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 1, Item: "pay the rent", Version: 4, Due: &newDue}, item)
+}
@@ -0,0 +1,397 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is an item found by Search, with the relevance it was ranked
+// by and the matched words highlighted in its text and notes.
+type SearchResult struct {
+	Item      ItemAndID `json:"item"`
+	Score     float64   `json:"score"`
+	Highlight Highlight `json:"highlight"`
+}
+
+// Highlight holds the item's text and an excerpt of its notes around the
+// first match, with every matched word wrapped in "**". Notes is empty if
+// they didn't match.
+type Highlight struct {
+	Item  string `json:"item"`
+	Notes string `json:"notes,omitempty"`
+}
+
+const (
+	// bm25K1 and bm25B are the usual BM25 parameters: how quickly repeating
+	// a word stops adding to the score, and how much longer items are
+	// penalized.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// itemTextWeight counts words in the item's text this many times as much
+	// as words in its notes and tags.
+	itemTextWeight = 2
+	// prefixWeight is the share of the score a word gets for only being the
+	// prefix of a word of the item, and minPrefixLength the length a word
+	// needs to be looked up as a prefix at all.
+	prefixWeight    = 0.5
+	minPrefixLength = 2
+	// excerptWords is the number of words in the excerpt of the notes,
+	// starting excerptContext words in front of the first match.
+	excerptWords   = 16
+	excerptContext = 4
+	highlightMark  = "**"
+)
+
+// stopWords are too common to be worth searching for; they are neither
+// indexed nor required to match.
+var stopWords = map[string]bool{
+	"a": true, "about": true, "an": true, "and": true, "are": true, "as": true,
+	"at": true, "be": true, "but": true, "by": true, "for": true, "from": true,
+	"has": true, "have": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "this": true,
+	"to": true, "was": true, "were": true, "will": true, "with": true,
+}
+
+// Search returns the items whose text, notes or tags contain every word of
+// the query, most relevant first. Words are compared case-insensitively by
+// their English stem, so "rotating" finds "rotation", and a word also
+// matches the words it is a prefix of, for less. Items are ranked with
+// BM25. A query without any words to search for is invalid.
+func (il *ItemList) Search(query string) ([]SearchResult, error) {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	index := il.index
+	if index == nil {
+		index = newSearchIndex(il.items)
+	}
+
+	hits, err := index.search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SearchResult{}
+	for _, hit := range hits {
+		i := indexOf(il.items, hit.id)
+		if i < 0 {
+			continue
+		}
+
+		item := il.items[i]
+		results = append(results, SearchResult{
+			Item:  item,
+			Score: hit.score,
+			Highlight: Highlight{
+				Item:  highlight(item.Item, hit.stems),
+				Notes: excerpt(item.Notes, hit.stems),
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// searchIndex is an inverted index of the items in a list. ItemList keeps it
+// up to date as changes are applied, so searching doesn't have to go through
+// every item.
+type searchIndex struct {
+	docs map[int]searchDoc
+	// postings maps every stem to the items containing it and the weighted
+	// number of times it occurs in each.
+	postings map[string]map[int]float64
+	// words counts the items containing each word; sortedWords holds the
+	// same words in order to look up the ones starting with a prefix.
+	words       map[string]int
+	sortedWords []string
+	totalLength float64
+}
+
+type searchDoc struct {
+	length float64
+	words  map[string]float64
+}
+
+type searchHit struct {
+	id    int
+	score float64
+	// stems are the stems of the item that matched the query.
+	stems map[string]bool
+}
+
+// searchToken is a word of a text and its position in it.
+type searchToken struct {
+	word       string
+	start, end int
+}
+
+func newSearchIndex(items []ItemAndID) *searchIndex {
+	index := &searchIndex{
+		docs:     map[int]searchDoc{},
+		postings: map[string]map[int]float64{},
+		words:    map[string]int{},
+	}
+	for _, item := range items {
+		index.put(item)
+	}
+
+	return index
+}
+
+// update indexes the items the changes touched, which have been applied to
+// items already, and drops the ones that are no longer in the list.
+func (index *searchIndex) update(items []ItemAndID, changes []change) {
+	for _, id := range changedIDs(changes) {
+		if i := indexOf(items, id); i >= 0 {
+			index.put(items[i])
+		} else {
+			index.remove(id)
+		}
+	}
+}
+
+func (index *searchIndex) put(item ItemAndID) {
+	index.remove(item.ID)
+
+	doc := searchDoc{words: map[string]float64{}}
+	add := func(text string, weight float64) {
+		for _, token := range tokenizeSearchText(text) {
+			if !stopWords[token.word] {
+				doc.words[token.word] += weight
+				doc.length += weight
+			}
+		}
+	}
+	add(item.Item, itemTextWeight)
+	add(item.Notes, 1)
+	for _, tag := range item.Tags {
+		add(tag, 1)
+	}
+
+	for word, count := range doc.words {
+		stem := Stem(word)
+		if index.postings[stem] == nil {
+			index.postings[stem] = map[int]float64{}
+		}
+		index.postings[stem][item.ID] += count
+
+		index.words[word]++
+		if index.words[word] == 1 {
+			i := sort.SearchStrings(index.sortedWords, word)
+			index.sortedWords = append(index.sortedWords, "")
+			copy(index.sortedWords[i+1:], index.sortedWords[i:])
+			index.sortedWords[i] = word
+		}
+	}
+
+	index.docs[item.ID] = doc
+	index.totalLength += doc.length
+}
+
+func (index *searchIndex) remove(id int) {
+	doc, ok := index.docs[id]
+	if !ok {
+		return
+	}
+
+	for word := range doc.words {
+		stem := Stem(word)
+		delete(index.postings[stem], id)
+		if len(index.postings[stem]) == 0 {
+			delete(index.postings, stem)
+		}
+
+		index.words[word]--
+		if index.words[word] == 0 {
+			delete(index.words, word)
+			i := sort.SearchStrings(index.sortedWords, word)
+			index.sortedWords = append(index.sortedWords[:i], index.sortedWords[i+1:]...)
+		}
+	}
+
+	delete(index.docs, id)
+	index.totalLength -= doc.length
+}
+
+// search returns the items matching every word of the query, most relevant
+// first.
+func (index *searchIndex) search(query string) ([]searchHit, error) {
+	var words []string
+	seen := map[string]bool{}
+	for _, token := range tokenizeSearchText(query) {
+		if !stopWords[token.word] && !seen[token.word] {
+			seen[token.word] = true
+			words = append(words, token.word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, NewFieldError(ErrValidation, "q", "q (%q) has no words to search for", query)
+	}
+
+	hits := map[int]*searchHit{}
+	for i, word := range words {
+		// the score of a word is that of the best of the stems it matches
+		scores := map[int]float64{}
+		for stem, weight := range index.stemsMatching(word) {
+			for id := range index.postings[stem] {
+				if i > 0 && hits[id] == nil {
+					continue
+				}
+				if hits[id] == nil {
+					hits[id] = &searchHit{id: id, stems: map[string]bool{}}
+				}
+
+				hits[id].stems[stem] = true
+				if score := weight * index.bm25(stem, id); score > scores[id] {
+					scores[id] = score
+				}
+			}
+		}
+
+		// items have to match every word
+		for id, hit := range hits {
+			if _, ok := scores[id]; !ok {
+				delete(hits, id)
+				continue
+			}
+			hit.score += scores[id]
+		}
+		if len(hits) == 0 {
+			break
+		}
+	}
+
+	result := make([]searchHit, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, *hit)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].score != result[j].score {
+			return result[i].score > result[j].score
+		}
+		return result[i].id < result[j].id
+	})
+
+	return result, nil
+}
+
+// stemsMatching returns the indexed stems the word matches with their
+// weight: its own stem fully and the stems of the words it is a prefix of
+// with prefixWeight.
+func (index *searchIndex) stemsMatching(word string) map[string]float64 {
+	stems := map[string]float64{}
+	if len([]rune(word)) >= minPrefixLength {
+		for i := sort.SearchStrings(index.sortedWords, word); i < len(index.sortedWords) && strings.HasPrefix(index.sortedWords[i], word); i++ {
+			stems[Stem(index.sortedWords[i])] = prefixWeight
+		}
+	}
+
+	if stem := Stem(word); index.postings[stem] != nil {
+		stems[stem] = 1
+	}
+
+	return stems
+}
+
+// bm25 scores the stem for the item it occurs in.
+func (index *searchIndex) bm25(stem string, id int) float64 {
+	n := float64(len(index.docs))
+	df := float64(len(index.postings[stem]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	tf := index.postings[stem][id]
+	norm := index.docs[id].length / (index.totalLength / n)
+
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*norm))
+}
+
+// tokenizeSearchText splits the text into lower case words of letters and
+// digits.
+func tokenizeSearchText(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		} else if !isWordRune && start >= 0 {
+			tokens = append(tokens, searchToken{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// highlight wraps the words of the text whose stem is one of the stems in
+// highlightMark.
+func highlight(text string, stems map[string]bool) string {
+	return highlightTokens(text, tokenizeSearchText(text), stems)
+}
+
+func highlightTokens(text string, tokens []searchToken, stems map[string]bool) string {
+	var b strings.Builder
+	last := 0
+	for _, token := range tokens {
+		if !stems[Stem(token.word)] {
+			continue
+		}
+
+		b.WriteString(text[last:token.start])
+		b.WriteString(highlightMark + text[token.start:token.end] + highlightMark)
+		last = token.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// excerpt returns excerptWords words of the text around the first word
+// whose stem is one of the stems, highlighted and on a single line, or ""
+// if there is none.
+func excerpt(text string, stems map[string]bool) string {
+	tokens := tokenizeSearchText(text)
+
+	first := -1
+	for i, token := range tokens {
+		if stems[Stem(token.word)] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := first - excerptContext
+	if start < 0 {
+		start = 0
+	}
+	end := start + excerptWords
+	if end > len(tokens) {
+		end = len(tokens)
+	}
+
+	// highlighting leaves the text in front of the first token as it is
+	cut := len(text)
+	if end < len(tokens) {
+		cut = tokens[end-1].end
+	}
+	window := highlightTokens(text[:cut], tokens[start:end], stems)[tokens[start].start:]
+
+	e := strings.Join(strings.Fields(window), " ")
+	if start > 0 {
+		e = "…" + e
+	}
+	if end < len(tokens) {
+		e += "…"
+	}
+
+	return e
+}
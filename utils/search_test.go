@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	testTable := []struct {
+		word         string
+		expectedStem string
+	}{
+		{word: "caresses", expectedStem: "caress"},
+		{word: "ponies", expectedStem: "poni"},
+		{word: "cats", expectedStem: "cat"},
+		{word: "agreed", expectedStem: "agre"},
+		{word: "hopping", expectedStem: "hop"},
+		{word: "filing", expectedStem: "file"},
+		{word: "happy", expectedStem: "happi"},
+		{word: "relational", expectedStem: "relat"},
+		{word: "generalizations", expectedStem: "gener"},
+		{word: "hopefulness", expectedStem: "hope"},
+		{word: "adjustment", expectedStem: "adjust"},
+		{word: "controll", expectedStem: "control"},
+		{word: "rotate", expectedStem: "rotat"},
+		{word: "rotating", expectedStem: "rotat"},
+		{word: "rotation", expectedStem: "rotat"},
+		{word: "is", expectedStem: "is"},
+		{word: "v2", expectedStem: "v2"},
+		{word: "café", expectedStem: "café"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.word, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedStem, Stem(testCase.word))
+		})
+	}
+}
+
+func searchList() *ItemList {
+	il := NewItemList()
+	il.CreateItem("Rotate the TLS certs", WithNotes("The cert rotation runbook\nis in the wiki."))
+	il.CreateItem("Renew the domain", WithTags("certs"))
+	il.CreateItem("Buy milk")
+	il.CreateItem("Write the rotation schedule")
+	il.CreateItem("Read about rotations", WithNotes("Rotating things: rotation of crops, of shifts and of rotors. Rotations everywhere."))
+
+	return il
+}
+
+func resultIDs(results []SearchResult) []int {
+	ids := []int{}
+	for _, result := range results {
+		ids = append(ids, result.Item.ID)
+	}
+
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	testTable := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedError error
+	}{
+		{
+			name:        "stems and case",
+			query:       "ROTATING",
+			expectedIDs: []int{5, 1, 4},
+		},
+		{
+			name:        "every word has to match",
+			query:       "cert rotation",
+			expectedIDs: []int{1},
+		},
+		{
+			name:        "tags",
+			query:       "certs",
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "prefix",
+			query:       "mil",
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "prefix of a longer word than the stem",
+			query:       "rotati",
+			expectedIDs: []int{5, 1, 4},
+		},
+		{
+			name:        "stop words are left out",
+			query:       "the milk",
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "no match",
+			query:       "zebra",
+			expectedIDs: []int{},
+		},
+		{
+			name:          "only stop words",
+			query:         "the, and",
+			expectedError: NewFieldError(ErrValidation, "q", "q (%q) has no words to search for", "the, and"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			results, err := searchList().Search(testCase.query)
+
+			assert.Equal(t, testCase.expectedError, err)
+			if err == nil {
+				assert.Equal(t, testCase.expectedIDs, resultIDs(results))
+			}
+		})
+	}
+}
+
+func TestSearch_Highlight(t *testing.T) {
+	results, err := searchList().Search("cert rot")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, Highlight{
+		Item:  "**Rotate** the TLS **certs**",
+		Notes: "The **cert** **rotation** runbook is in the wiki.",
+	}, results[0].Highlight)
+
+	results, err = searchList().Search("shifts")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, Highlight{
+		Item:  "Read about rotations",
+		Notes: "…rotation of crops, of **shifts** and of rotors. Rotations everywhere.",
+	}, results[0].Highlight)
+}
+
+func TestSearch_IndexFollowsChanges(t *testing.T) {
+	il := searchList()
+	il.UpdateItem(3, "Buy oat milk")
+	il.AddTags(4, "ops")
+	il.DeleteItem(2)
+	il.DeleteItem(1)
+	il.RestoreItem(1)
+	il.Undo()
+	il.Redo()
+	il.CompleteItem(5)
+
+	assert.Equal(t, newSearchIndex(il.items), il.index)
+
+	results, err := il.Search("oat")
+	require.Nil(t, err)
+	assert.Equal(t, []int{3}, resultIDs(results))
+
+	results, err = il.Search("renew")
+	require.Nil(t, err)
+	assert.Equal(t, []int{}, resultIDs(results))
+}
+
+func TestSearch_Reopened(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 2)
+	require.Nil(t, err)
+	store.CreateItem("Rotate the certs")
+	store.CreateItem("Buy milk")
+	store.CreateItem("Renew the certs")
+	store.DeleteItem(1)
+	require.Nil(t, store.Close())
+
+	store, err = NewWALStore(dir, 2)
+	require.Nil(t, err)
+	defer store.Close()
+
+	results, err := store.Search("certs")
+	require.Nil(t, err)
+	assert.Equal(t, []int{3}, resultIDs(results))
+}
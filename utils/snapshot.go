@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// listSnapshot is the full state of an ItemList as written to disk.
+type listSnapshot struct {
+	NextID int           `json:"next_id"`
+	Items  []ItemAndID   `json:"items"`
+	Trash  []TrashedItem `json:"trash,omitempty"`
+	// History holds the revisions of every item in the order they were made.
+	History []Revision `json:"history,omitempty"`
+	// Undo and Redo are the operations that can be undone and redone, most
+	// recent last.
+	Undo []operation `json:"undo,omitempty"`
+	Redo []operation `json:"redo,omitempty"`
+	// Seq is the sequence number of the last write-ahead log record
+	// included in a WALStore snapshot.
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// snapshotFile wraps the encoded snapshot with a checksum so that corrupt or
+// partially written files are detected when they are loaded.
+type snapshotFile struct {
+	Checksum string          `json:"checksum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// snapshot returns a copy of the list's state. Must be called with il.m locked.
+func (il *ItemList) snapshot() listSnapshot {
+	return listSnapshot{
+		NextID:  il.nextID,
+		Items:   copyItems(il.items),
+		Trash:   copyTrash(il.trash),
+		History: copyHistory(il.history),
+		Undo:    copyOperations(il.undo),
+		Redo:    copyOperations(il.redo),
+	}
+}
+
+// restore replaces the list's state with the snapshot after validating it.
+// Must be called with il.m locked for writing, or before the list is shared.
+func (il *ItemList) restore(s listSnapshot) error {
+	if s.NextID < 1 {
+		return fmt.Errorf("next id (%v) is less than 1", s.NextID)
+	}
+
+	// trashed items keep their IDs, so they can't be taken by other items
+	ids := []int{}
+	for _, item := range s.Items {
+		ids = append(ids, item.ID)
+	}
+	for _, item := range s.Trash {
+		ids = append(ids, item.ID)
+	}
+
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if id < 1 || id >= s.NextID {
+			return fmt.Errorf("item id (%v) is outside of the assigned range [1, %v)", id, s.NextID)
+		}
+		if seen[id] {
+			return fmt.Errorf("item id (%v) appears more than once", id)
+		}
+		seen[id] = true
+	}
+
+	il.items = copyItems(s.Items)
+	il.trash = copyTrash(s.Trash)
+	il.history = copyHistory(s.History)
+	il.nextID = s.NextID
+	il.undo = lastOperations(copyOperations(s.Undo), il.undoDepth)
+	il.redo = lastOperations(copyOperations(s.Redo), il.undoDepth)
+	il.index = newSearchIndex(il.items)
+
+	return nil
+}
+
+func encodeSnapshot(s listSnapshot) ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(snapshotFile{
+		Checksum: checksum(data),
+		Data:     data,
+	})
+}
+
+func decodeSnapshot(b []byte) (listSnapshot, error) {
+	if len(b) == 0 {
+		return listSnapshot{}, fmt.Errorf("file is empty")
+	}
+
+	var f snapshotFile
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return listSnapshot{}, fmt.Errorf("file is corrupt or partially written: %v", err)
+	}
+
+	if f.Checksum != checksum(f.Data) {
+		return listSnapshot{}, fmt.Errorf("file is corrupt: checksum mismatch")
+	}
+
+	var s listSnapshot
+	err = json.Unmarshal(f.Data, &s)
+	if err != nil {
+		return listSnapshot{}, fmt.Errorf("file is corrupt: %v", err)
+	}
+
+	return s, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic replaces the file at path with data. The data is written to
+// a temporary file in the same directory which is synced and then renamed
+// over path, so readers see either the old or the new contents, never a mix.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir flushes directory entries, e.g. a rename, to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
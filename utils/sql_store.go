@@ -0,0 +1,740 @@
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+	"sort"
+	"time"
+)
+
+// sqlMigrations are applied in order to bring the schema up to date; the
+// number of applied migrations is kept in the database's user_version.
+// Never edit a migration that has been released, append a new one instead.
+var sqlMigrations = []string{
+	`CREATE TABLE items (
+		id       INTEGER PRIMARY KEY,
+		item     TEXT NOT NULL,
+		position INTEGER NOT NULL
+	);
+	CREATE TABLE list_state (
+		next_id INTEGER NOT NULL
+	);
+	INSERT INTO list_state (next_id) VALUES (1);`,
+	`ALTER TABLE items ADD COLUMN done INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE items ADD COLUMN completed_at TEXT;`,
+	`ALTER TABLE items ADD COLUMN due TEXT;`,
+	`ALTER TABLE items ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE item_tags (
+		item_id INTEGER NOT NULL,
+		tag     TEXT NOT NULL,
+		PRIMARY KEY (item_id, tag)
+	);
+	CREATE INDEX item_tags_tag ON item_tags (tag);`,
+	`ALTER TABLE items ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE item_blockers (
+		item_id    INTEGER NOT NULL,
+		blocker_id INTEGER NOT NULL,
+		PRIMARY KEY (item_id, blocker_id)
+	);
+	CREATE INDEX item_blockers_blocker_id ON item_blockers (blocker_id);`,
+	`ALTER TABLE items ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+	ALTER TABLE items ADD COLUMN recurrence_start TEXT;`,
+	`ALTER TABLE items ADD COLUMN notes TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE items ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE items ADD COLUMN deleted_at TEXT;`,
+	`CREATE TABLE item_revisions (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		at      TEXT NOT NULL,
+		op      TEXT NOT NULL,
+		actor   TEXT NOT NULL DEFAULT '',
+		old     TEXT,
+		new     TEXT
+	);
+	CREATE INDEX item_revisions_item_id ON item_revisions (item_id);`,
+	`ALTER TABLE item_revisions ADD COLUMN before_id INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE operations (
+		seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+		stack     TEXT NOT NULL,
+		operation TEXT NOT NULL
+	);`,
+}
+
+// SQLStore is an ItemList persisted to an embedded SQLite database. The list
+// is loaded from the database on startup and every change is written to it
+// in a transaction before it is applied, so the database can be queried
+// directly for reporting. The items and their count are read from the
+// database, so rows changed in it directly show up in them; the other reads,
+// and the checks mutations make, are served from the list loaded on startup.
+// Items in the trash
+// stay in the items table with their deleted_at set, and the revisions of
+// the items are kept in item_revisions with their old and new values as JSON.
+// The operations that can be undone and redone are kept as JSON in
+// operations, on the "undo" and "redo" stacks.
+type SQLStore struct {
+	*ItemList
+	db *sql.DB
+}
+
+var _ Store = (*SQLStore)(nil)
+
+// sqlItemColumns are the columns of items that scanItem reads.
+const sqlItemColumns = "id, item, version, notes, done, completed_at, due, priority, parent_id, recurrence, recurrence_start, deleted_at"
+
+// NewSQLStore opens, or creates, the database at path and migrates its
+// schema. ":memory:" opens a database that only lives as long as the store.
+func NewSQLStore(path string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// changes are serialized by the list's lock anyway, and a single
+	// connection keeps ":memory:" databases from being opened twice
+	db.SetMaxOpenConns(1)
+
+	ss := &SQLStore{
+		ItemList: NewItemList(),
+		db:       db,
+	}
+
+	err = ss.migrate()
+	if err == nil {
+		err = ss.load()
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error opening database %v: %v", path, err)
+	}
+
+	ss.journal = ss
+
+	return ss, nil
+}
+
+func (ss *SQLStore) Close() error {
+	ss.m.Lock()
+	defer ss.m.Unlock()
+
+	ss.closed = true
+
+	return ss.db.Close()
+}
+
+func (ss *SQLStore) migrate() error {
+	var version int
+	err := ss.db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	if version > len(sqlMigrations) {
+		return fmt.Errorf("schema version (%v) is newer than this server supports (%v)", version, len(sqlMigrations))
+	}
+
+	for ; version < len(sqlMigrations); version++ {
+		tx, err := ss.db.Begin()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(sqlMigrations[version])
+		if err == nil {
+			// PRAGMA doesn't accept bind parameters
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error applying migration %v: %v", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (ss *SQLStore) load() error {
+	s := listSnapshot{Items: []ItemAndID{}, Trash: []TrashedItem{}}
+
+	err := ss.db.QueryRow("SELECT next_id FROM list_state").Scan(&s.NextID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := ss.db.Query("SELECT " + sqlItemColumns + " FROM items ORDER BY position")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var items []ItemAndID
+	var deletedAts []*time.Time
+	for rows.Next() {
+		item, deleted, err := scanItem(rows)
+		if err != nil {
+			return err
+		}
+
+		items = append(items, item)
+		deletedAts = append(deletedAts, deleted)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	err = ss.loadTags(items)
+	if err == nil {
+		err = ss.loadBlockers(items)
+	}
+	if err == nil {
+		s.History, err = ss.loadHistory()
+	}
+	if err == nil {
+		s.Undo, s.Redo, err = ss.loadOperations()
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if deletedAts[i] == nil {
+			s.Items = append(s.Items, item)
+		} else {
+			s.Trash = append(s.Trash, TrashedItem{ItemAndID: item, DeletedAt: *deletedAts[i]})
+		}
+	}
+
+	// the trash is kept in the order the items were deleted in
+	sort.SliceStable(s.Trash, func(i, j int) bool {
+		return s.Trash[i].DeletedAt.Before(s.Trash[j].DeletedAt)
+	})
+
+	return ss.restore(s)
+}
+
+// scanItem reads an item selected with sqlItemColumns along with the time
+// it was deleted at, which is nil unless it is in the trash.
+func scanItem(rows *sql.Rows) (ItemAndID, *time.Time, error) {
+	var item ItemAndID
+	var completedAt, due, recurrenceStart, deletedAt sql.NullString
+	err := rows.Scan(&item.ID, &item.Item, &item.Version, &item.Notes, &item.Done, &completedAt, &due, &item.Priority, &item.ParentID, &item.Recurrence, &recurrenceStart, &deletedAt)
+	if err != nil {
+		return ItemAndID{}, nil, err
+	}
+
+	item.CompletedAt, err = parseNullTime(completedAt)
+	if err == nil {
+		item.Due, err = parseNullTime(due)
+	}
+	if err == nil {
+		item.RecurrenceStart, err = parseNullTime(recurrenceStart)
+	}
+	var deleted *time.Time
+	if err == nil {
+		deleted, err = parseNullTime(deletedAt)
+	}
+	if err != nil {
+		return ItemAndID{}, nil, fmt.Errorf("item %v: %v", item.ID, err)
+	}
+
+	return item, deleted, nil
+}
+
+func (ss *SQLStore) ReadItem(id int) (ItemAndID, error) {
+	if id < 1 {
+		return ItemAndID{}, NewError(ErrInvalidID, "id is less than 1")
+	}
+
+	ss.m.RLock()
+	defer ss.m.RUnlock()
+
+	items, err := ss.selectItems("id = ?", id)
+	if err != nil {
+		return ItemAndID{}, fmt.Errorf("error reading from database: %v", err)
+	}
+	if len(items) == 0 {
+		return ItemAndID{}, NewError(ErrNotFound, "item with id (%v) does not exist", id)
+	}
+
+	return items[0], nil
+}
+
+func (ss *SQLStore) ReadAll() []ItemAndID {
+	ss.m.RLock()
+	defer ss.m.RUnlock()
+
+	items, err := ss.selectItems("1 = 1")
+	if err != nil {
+		logrus.Warnf("error reading from database, serving the items from memory: %v", err)
+		return copyItems(ss.items)
+	}
+
+	return items
+}
+
+// Find returns the items matching the query in the query's sort order. The
+// list as of a time is rebuilt from the revisions in the database.
+func (ss *SQLStore) Find(q Query) []ItemAndID {
+	ss.m.RLock()
+	defer ss.m.RUnlock()
+
+	var source []ItemAndID
+	var err error
+	if q.AsOf != nil {
+		var history []Revision
+		history, err = ss.loadHistory()
+		source = itemsAsOf(history, *q.AsOf)
+	} else {
+		source, err = ss.selectItems("1 = 1")
+	}
+	if err != nil {
+		logrus.Warnf("error reading from database, serving the items from memory: %v", err)
+		source = ss.items
+		if q.AsOf != nil {
+			source = ss.itemsAsOf(*q.AsOf)
+		}
+	}
+
+	return q.find(source)
+}
+
+func (ss *SQLStore) Count() int {
+	ss.m.RLock()
+	defer ss.m.RUnlock()
+
+	var count int
+	err := ss.db.QueryRow("SELECT COUNT(*) FROM items WHERE deleted_at IS NULL").Scan(&count)
+	if err != nil {
+		logrus.Warnf("error reading from database, counting the items in memory: %v", err)
+		return len(ss.items)
+	}
+
+	return count
+}
+
+// selectItems returns the items in the list, not those in the trash, that
+// the where clause selects, in list order and with their tags and blockers.
+func (ss *SQLStore) selectItems(where string, args ...interface{}) ([]ItemAndID, error) {
+	selected := "SELECT id FROM items WHERE deleted_at IS NULL AND (" + where + ")"
+
+	rows, err := ss.db.Query("SELECT "+sqlItemColumns+" FROM items WHERE deleted_at IS NULL AND ("+where+") ORDER BY position", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ItemAndID{}
+	indexes := map[int]int{}
+	for rows.Next() {
+		item, _, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes[item.ID] = len(items)
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = ss.db.Query("SELECT item_id, tag FROM item_tags WHERE item_id IN ("+selected+") ORDER BY item_id, tag", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		err = rows.Scan(&id, &tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if index, ok := indexes[id]; ok {
+			items[index].Tags = append(items[index].Tags, tag)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// only one side of an edge may have been selected
+	rows, err = ss.db.Query("SELECT item_id, blocker_id FROM item_blockers WHERE item_id IN ("+selected+") OR blocker_id IN ("+selected+") ORDER BY item_id, blocker_id", append(args, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, blockerID int
+		err = rows.Scan(&id, &blockerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if index, ok := indexes[id]; ok {
+			items[index].BlockedBy = append(items[index].BlockedBy, blockerID)
+		}
+		if index, ok := indexes[blockerID]; ok {
+			items[index].Blocks = addID(items[index].Blocks, id)
+		}
+	}
+
+	return items, rows.Err()
+}
+
+func (ss *SQLStore) loadTags(items []ItemAndID) error {
+	indexes := map[int]int{}
+	for i, item := range items {
+		indexes[item.ID] = i
+	}
+
+	rows, err := ss.db.Query("SELECT item_id, tag FROM item_tags ORDER BY item_id, tag")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		err = rows.Scan(&id, &tag)
+		if err != nil {
+			return err
+		}
+
+		index, ok := indexes[id]
+		if !ok {
+			return fmt.Errorf("tag (%v) belongs to item %v which does not exist", tag, id)
+		}
+		items[index].Tags = append(items[index].Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// loadBlockers fills in both sides of the blocker edges, which are only
+// stored once.
+func (ss *SQLStore) loadBlockers(items []ItemAndID) error {
+	indexes := map[int]int{}
+	for i, item := range items {
+		indexes[item.ID] = i
+	}
+
+	rows, err := ss.db.Query("SELECT item_id, blocker_id FROM item_blockers ORDER BY item_id, blocker_id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, blockerID int
+		err = rows.Scan(&id, &blockerID)
+		if err != nil {
+			return err
+		}
+
+		index, ok := indexes[id]
+		blockerIndex, blockerOK := indexes[blockerID]
+		if !ok || !blockerOK {
+			return fmt.Errorf("item %v blocked by item %v refers to an item which does not exist", id, blockerID)
+		}
+		items[index].BlockedBy = append(items[index].BlockedBy, blockerID)
+		items[blockerIndex].Blocks = addID(items[blockerIndex].Blocks, id)
+	}
+
+	return rows.Err()
+}
+
+func (ss *SQLStore) loadHistory() ([]Revision, error) {
+	rows, err := ss.db.Query("SELECT item_id, at, op, actor, old, new, before_id FROM item_revisions ORDER BY seq")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []Revision{}
+	for rows.Next() {
+		var revision Revision
+		var at string
+		var old, new sql.NullString
+		err = rows.Scan(&revision.ItemID, &at, &revision.Op, &revision.Actor, &old, &new, &revision.Before)
+		if err != nil {
+			return nil, err
+		}
+
+		revision.At, err = time.Parse(time.RFC3339Nano, at)
+		if err == nil {
+			revision.Old, err = parseNullItem(old)
+		}
+		if err == nil {
+			revision.New, err = parseNullItem(new)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("revision of item %v: %v", revision.ItemID, err)
+		}
+
+		history = append(history, revision)
+	}
+
+	return history, rows.Err()
+}
+
+// loadOperations returns the operations that can be undone and those that
+// can be redone, most recent last.
+func (ss *SQLStore) loadOperations() ([]operation, []operation, error) {
+	rows, err := ss.db.Query("SELECT seq, stack, operation FROM operations ORDER BY seq")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var undo, redo []operation
+	for rows.Next() {
+		var seq int
+		var stack, b string
+		err = rows.Scan(&seq, &stack, &b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var op operation
+		err = json.Unmarshal([]byte(b), &op)
+		if err != nil {
+			return nil, nil, fmt.Errorf("operation %v: %v", seq, err)
+		}
+
+		switch stack {
+		case "undo":
+			undo = append(undo, op)
+		case "redo":
+			redo = append(redo, op)
+		default:
+			return nil, nil, fmt.Errorf("operation %v is on an unknown stack (%v)", seq, stack)
+		}
+	}
+
+	return undo, redo, rows.Err()
+}
+
+func (ss *SQLStore) record(il *ItemList, changes []change) error {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		err = execChange(tx, c)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error writing to database: %v", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error writing to database: %v", err)
+	}
+
+	return nil
+}
+
+func execChange(tx *sql.Tx, c change) error {
+	var err error
+
+	switch c.Op {
+	case opPut:
+		err = upsertItem(tx, *c.Item, nil, c.Before)
+	case opTrash:
+		err = upsertItem(tx, *c.Item, c.DeletedAt, 0)
+	case opMove:
+		_, err = tx.Exec(`UPDATE items SET position = position + 1
+			WHERE position >= (SELECT position FROM items WHERE id = ? AND deleted_at IS NULL)`, c.Before)
+		if err == nil {
+			_, err = tx.Exec(`UPDATE items SET position = COALESCE(
+				(SELECT position - 1 FROM items WHERE id = ? AND deleted_at IS NULL),
+				(SELECT MAX(position) + 1 FROM items))
+				WHERE id = ?`, c.Before, c.ID)
+		}
+	case opRevision:
+		var old, new sql.NullString
+		old, err = formatNullItem(c.Revision.Old)
+		if err == nil {
+			new, err = formatNullItem(c.Revision.New)
+		}
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO item_revisions (item_id, at, op, actor, old, new, before_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				c.Revision.ItemID, c.Revision.At.UTC().Format(time.RFC3339Nano), string(c.Revision.Op), c.Revision.Actor, old, new, c.Revision.Before)
+		}
+	case opPurge, opRemove:
+		_, err = tx.Exec("DELETE FROM items WHERE id = ?", c.ID)
+		if err == nil {
+			_, err = tx.Exec("DELETE FROM item_tags WHERE item_id = ?", c.ID)
+		}
+		if err == nil {
+			_, err = tx.Exec("DELETE FROM item_blockers WHERE item_id = ?", c.ID)
+		}
+		if err == nil && c.Op == opPurge {
+			_, err = tx.Exec("DELETE FROM item_revisions WHERE item_id = ?", c.ID)
+		}
+		if err == nil && c.Op == opPurge {
+			_, err = tx.Exec(`DELETE FROM operations WHERE EXISTS
+				(SELECT 1 FROM json_each(operation, '$.before') WHERE json_extract(value, '$.id') = ?)`, c.ID)
+		}
+	case opPushUndo:
+		_, err = tx.Exec("DELETE FROM operations WHERE stack = 'redo'")
+		if err == nil {
+			err = pushOperation(tx, "undo", *c.Operation, c.Depth)
+		}
+	case opUndo:
+		err = popOperation(tx, "undo")
+		if err == nil {
+			err = pushOperation(tx, "redo", *c.Operation, c.Depth)
+		}
+	case opRedo:
+		err = popOperation(tx, "redo")
+		if err == nil {
+			err = pushOperation(tx, "undo", *c.Operation, c.Depth)
+		}
+	}
+
+	return err
+}
+
+// pushOperation puts the operation on top of the stack, keeping the depth
+// most recent operations on it.
+func pushOperation(tx *sql.Tx, stack string, op operation, depth int) error {
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO operations (stack, operation) VALUES (?, ?)", stack, string(b))
+	if err == nil {
+		_, err = tx.Exec(`DELETE FROM operations WHERE stack = ? AND seq NOT IN
+			(SELECT seq FROM operations WHERE stack = ? ORDER BY seq DESC LIMIT ?)`, stack, stack, depth)
+	}
+
+	return err
+}
+
+// popOperation takes the operation on top of the stack off it.
+func popOperation(tx *sql.Tx, stack string) error {
+	_, err := tx.Exec("DELETE FROM operations WHERE seq = (SELECT MAX(seq) FROM operations WHERE stack = ?)", stack)
+	return err
+}
+
+// upsertItem writes the item along with its tags and blockers; deletedAt is
+// nil unless the item is in the trash. An item that isn't in the list yet is
+// inserted in front of the item with the id before, see change.Before.
+func upsertItem(tx *sql.Tx, item ItemAndID, deletedAt *time.Time, before int) error {
+	// make room in front of that item
+	_, err := tx.Exec(`UPDATE items SET position = position + 1
+		WHERE position >= (SELECT position FROM items WHERE id = ? AND deleted_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM items WHERE id = ? AND deleted_at IS NULL)`, before, item.ID)
+	if err != nil {
+		return err
+	}
+
+	// new and restored items go to the free position or the end of the list,
+	// existing ones keep their place
+	_, err = tx.Exec(`INSERT INTO items (id, item, version, notes, done, completed_at, due, priority, parent_id, recurrence, recurrence_start, deleted_at, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(
+			(SELECT position - 1 FROM items WHERE id = ? AND deleted_at IS NULL),
+			(SELECT COALESCE(MAX(position), 0) + 1 FROM items)))
+		ON CONFLICT (id) DO UPDATE SET
+			item = excluded.item,
+			version = excluded.version,
+			notes = excluded.notes,
+			done = excluded.done,
+			completed_at = excluded.completed_at,
+			due = excluded.due,
+			priority = excluded.priority,
+			parent_id = excluded.parent_id,
+			recurrence = excluded.recurrence,
+			recurrence_start = excluded.recurrence_start,
+			deleted_at = excluded.deleted_at,
+			position = CASE WHEN items.deleted_at IS NULL THEN items.position ELSE excluded.position END`,
+		item.ID, item.Item, item.Version, item.Notes, item.Done, formatNullTime(item.CompletedAt), formatNullTime(item.Due), int(item.Priority), item.ParentID,
+		item.Recurrence, formatNullTime(item.RecurrenceStart), formatNullTime(deletedAt), before)
+	if err == nil {
+		_, err = tx.Exec("UPDATE list_state SET next_id = MAX(next_id, ?)", item.ID+1)
+	}
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM item_tags WHERE item_id = ?", item.ID)
+	}
+	for _, tag := range item.Tags {
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO item_tags (item_id, tag) VALUES (?, ?)", item.ID, tag)
+		}
+	}
+	// only the item's own blockers are stored, the other side is derived
+	// when loading
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM item_blockers WHERE item_id = ?", item.ID)
+	}
+	for _, blockerID := range item.BlockedBy {
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO item_blockers (item_id, blocker_id) VALUES (?, ?)", item.ID, blockerID)
+		}
+	}
+
+	return err
+}
+
+// revisions store items as JSON
+func formatNullItem(item *ItemAndID) (sql.NullString, error) {
+	if item == nil {
+		return sql.NullString{}, nil
+	}
+
+	b, err := json.Marshal(item)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func parseNullItem(s sql.NullString) (*ItemAndID, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	var item ItemAndID
+	err := json.Unmarshal([]byte(s.String), &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// times are stored as RFC 3339 text in UTC
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: t.UTC().Format(time.RFC3339Nano), Valid: true}
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
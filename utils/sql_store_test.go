@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLStore_PersistsMutations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	due := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+
+	store.CreateItem("abc")
+	store.CreateItem("bcd")
+	store.CreateItem("cdf", WithPriority(PriorityHigh))
+	_, err = store.AddTags(2, "backend")
+	require.Nil(t, err)
+	_, err = store.AddTags(3, "oncall", "backend")
+	require.Nil(t, err)
+	weekly, err := ParseRecurrence("weekly")
+	require.Nil(t, err)
+	_, err = store.UpdateItem(1, "123", WithDue(due), WithRecurrence(weekly), WithNotes("line 1\nline 2"))
+	require.Nil(t, err)
+	_, err = store.DeleteItem(2)
+	require.Nil(t, err)
+	completed, err := store.CompleteItem(3)
+	require.Equal(t, PriorityHigh, completed.Priority)
+	require.Equal(t, []string{"backend", "oncall"}, completed.Tags)
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "123", Version: 2, Notes: "line 1\nline 2", Due: &due, Recurrence: "weekly", RecurrenceStart: &due}, completed}, store.ReadAll())
+
+	var count int
+	require.Nil(t, store.db.QueryRow("SELECT COUNT(*) FROM items WHERE item LIKE 'c%'").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// the deleted item keeps its tag in the trash
+	require.Nil(t, store.db.QueryRow("SELECT COUNT(*) FROM item_tags").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	_, err = store.DeleteAll()
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, []ItemAndID{}, store.ReadAll())
+
+	item, err := store.CreateItem("def")
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 4, Item: "def", Version: 1}, item)
+}
+
+func TestSQLStore_PersistsBlockers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	store.CreateItem("a")
+	store.CreateItem("b")
+	store.CreateItem("c")
+	_, err = store.AddBlocker(1, 2)
+	require.Nil(t, err)
+	_, err = store.AddBlocker(1, 3)
+	require.Nil(t, err)
+	_, err = store.AddBlocker(2, 3)
+	require.Nil(t, err)
+	_, err = store.DeleteItem(2)
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, []ItemAndID{
+		{ID: 1, Item: "a", Version: 4, BlockedBy: []int{3}},
+		{ID: 3, Item: "c", Version: 4, Blocks: []int{1}},
+	}, store.ReadAll())
+}
+
+func TestSQLStore_PersistsTrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	store.CreateItem("a")
+	store.CreateItem("b")
+	store.CreateItem("c")
+	_, err = store.AddTags(1, "backend")
+	require.Nil(t, err)
+	_, err = store.DeleteItem(1)
+	require.Nil(t, err)
+	_, err = store.DeleteItem(2)
+	require.Nil(t, err)
+	trash := store.Trash()
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	assert.Equal(t, trash, store.Trash())
+
+	// a restored item goes to the end of the list
+	_, err = store.RestoreItem(1)
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, []ItemAndID{
+		{ID: 3, Item: "c", Version: 1},
+		{ID: 1, Item: "a", Version: 3, Tags: []string{"backend"}},
+	}, store.ReadAll())
+	assert.Equal(t, []int{2}, trashedIDs(store.Trash()))
+}
+
+func TestSQLStore_PersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	store.CreateItem("a", ByActor("alice"))
+	store.UpdateItem(1, "b", WithNotes("notes"))
+	store.DeleteItem(1)
+	history, err := store.History(1)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(history))
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+
+	reopened, err := store.History(1)
+	require.Nil(t, err)
+	assert.Equal(t, history, reopened)
+}
+
+func TestSQLStore_UndoKeepsPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	store.CreateItem("a")
+	store.CreateItem("b")
+	store.CreateItem("c")
+	store.DeleteItem(1)
+	store.DeleteItem(2)
+	_, err = store.Undo()
+	require.Nil(t, err)
+	_, err = store.Undo()
+	require.Nil(t, err)
+	items := store.ReadAll()
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, items, store.ReadAll())
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "a", Version: 2}, {ID: 2, Item: "b", Version: 2}, {ID: 3, Item: "c", Version: 1}}, items)
+}
+
+func TestSQLStore_PersistsOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	store.CreateItem("a")
+	store.CreateItem("b")
+	store.CreateItem("c")
+	store.CreateItem("d")
+	store.DeleteItem(2)
+	store.MoveItem(4, MoveToPosition(1))
+	store.MoveItem(1, MoveAfter(3))
+	store.MoveItem(3, MoveToPosition(3))
+	store.RestoreItem(2)
+	require.Equal(t, []int{4, 1, 3, 2}, itemIDs(store.ReadAll()))
+	require.Nil(t, store.Close())
+
+	store, err = NewSQLStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, []int{4, 1, 3, 2}, itemIDs(store.ReadAll()))
+
+	// the moves are part of the history the list is rebuilt from
+	asOf := time.Now().Add(time.Second)
+	assert.Equal(t, []int{4, 1, 3, 2}, itemIDs(store.Find(Query{AsOf: &asOf})))
+}
+
+func TestSQLStore_ReadsFromDatabase(t *testing.T) {
+	store, err := NewSQLStore(":memory:")
+	require.Nil(t, err)
+	defer store.Close()
+
+	store.CreateItem("abc")
+	store.CreateItem("bcd")
+	store.CreateItem("cdf")
+	_, err = store.AddTags(2, "backend")
+	require.Nil(t, err)
+	_, err = store.AddBlocker(3, 2)
+	require.Nil(t, err)
+
+	// rows changed behind the store's back show up straight away
+	_, err = store.db.Exec("UPDATE items SET item = 'changed', done = 1 WHERE id = 2")
+	require.Nil(t, err)
+	_, err = store.db.Exec("DELETE FROM items WHERE id = 1")
+	require.Nil(t, err)
+
+	item, err := store.ReadItem(2)
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 2, Item: "changed", Version: 3, Done: true, Tags: []string{"backend"}, Blocks: []int{3}}, item)
+
+	_, err = store.ReadItem(1)
+	assert.Equal(t, NewError(ErrNotFound, "item with id (1) does not exist"), err)
+
+	assert.Equal(t, []int{2, 3}, itemIDs(store.ReadAll()))
+	assert.Equal(t, 2, store.Count())
+	assert.Equal(t, []int{3}, itemIDs(store.Find(Query{Status: StatusOpen})))
+	assert.Equal(t, []int{2}, itemIDs(store.Find(Query{Tags: []string{"backend"}})))
+}
+
+func TestSQLStore_Migrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+
+	store, err := NewSQLStore(path)
+	require.Nil(t, err)
+
+	var version int
+	require.Nil(t, store.db.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, len(sqlMigrations), version)
+
+	// a database from a newer server is refused rather than misread
+	_, err = store.db.Exec("PRAGMA user_version = 1000")
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	_, err = NewSQLStore(path)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import "time"
+
+// Store is the storage used by the HTTP handlers. ItemList is the in-memory
+// implementation; durable implementations must keep the same ID semantics,
+// i.e. IDs are assigned by CreateItem and never change or get reused.
+type Store interface {
+	CreateItem(item string, opts ...ItemOption) (ItemAndID, error)
+	ReadItem(id int) (ItemAndID, error)
+	ReadTree(id int) (ItemTree, error)
+	ReadAll() []ItemAndID
+	Find(q Query) []ItemAndID
+	Search(query string) ([]SearchResult, error)
+	UpdateItem(id int, newItem string, opts ...ItemOption) (ItemAndID, error)
+	MoveItem(id int, placement Placement) (ItemAndID, error)
+	CompleteItem(id int) (ItemAndID, error)
+	ReopenItem(id int) (ItemAndID, error)
+	AddTags(id int, tags ...string) (ItemAndID, error)
+	RemoveTag(id int, tag string) (ItemAndID, error)
+	Tags() []TagCount
+	AddBlocker(id int, blockerID int) (ItemAndID, error)
+	RemoveBlocker(id int, blockerID int) (ItemAndID, error)
+	Ready() []ItemAndID
+	DeleteItem(id int, opts ...ItemOption) (ItemAndID, error)
+	DeleteTree(id int, opts ...ItemOption) ([]ItemAndID, error)
+	DeleteAll() ([]ItemAndID, error)
+	Trash() []TrashedItem
+	RestoreItem(id int) (ItemAndID, error)
+	PurgeTrash(before time.Time) ([]TrashedItem, error)
+	History(id int) ([]Revision, error)
+	Undo() (Operation, error)
+	Redo() (Operation, error)
+	SetUndoDepth(depth int)
+	Count() int
+	// Close releases the store; mutations made after it fail.
+	Close() error
+}
+
+var _ Store = (*ItemList)(nil)
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// storeConstructors create every Store implementation; TestStores runs the
+// same behavioural tests against each of them.
+var storeConstructors = []struct {
+	name     string
+	newStore func(t *testing.T) Store
+}{
+	{
+		name: "memory",
+		newStore: func(t *testing.T) Store {
+			return NewItemList()
+		},
+	},
+	{
+		name: "json",
+		newStore: func(t *testing.T) Store {
+			store, err := NewFileStore(filepath.Join(t.TempDir(), "items.json"))
+			require.Nil(t, err)
+			return store
+		},
+	},
+	{
+		name: "wal",
+		newStore: func(t *testing.T) Store {
+			store, err := NewWALStore(t.TempDir(), 2)
+			require.Nil(t, err)
+			t.Cleanup(func() { store.Close() })
+			return store
+		},
+	},
+	{
+		name: "sqlite",
+		newStore: func(t *testing.T) Store {
+			store, err := NewSQLStore(":memory:")
+			require.Nil(t, err)
+			t.Cleanup(func() { store.Close() })
+			return store
+		},
+	},
+}
+
+func TestStores(t *testing.T) {
+	for _, constructor := range storeConstructors {
+		t.Run(constructor.name, func(t *testing.T) {
+			testStore(t, constructor.newStore)
+		})
+	}
+}
+
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Run("create and read", func(t *testing.T) {
+		store := newStore(t)
+
+		assert.Equal(t, []ItemAndID{}, store.ReadAll())
+		assert.Equal(t, 0, store.Count())
+
+		item, err := store.CreateItem("abc")
+		require.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 1, Item: "abc", Version: 1}, item)
+
+		item, err = store.CreateItem("{hello:world}")
+		require.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 2, Item: "{hello:world}", Version: 1}, item)
+
+		item, err = store.ReadItem(2)
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 2, Item: "{hello:world}", Version: 1}, item)
+
+		_, err = store.ReadItem(0)
+		assert.Equal(t, NewError(ErrInvalidID, "id is less than 1"), err)
+		_, err = store.ReadItem(3)
+		assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 3), err)
+
+		assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "{hello:world}", Version: 1}}, store.ReadAll())
+		assert.Equal(t, 2, store.Count())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd")
+
+		item, err := store.UpdateItem(2, "123")
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 2, Item: "123", Version: 2}, item)
+
+		_, err = store.UpdateItem(3, "456")
+		assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 3), err)
+
+		assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "123", Version: 2}}, store.ReadAll())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd")
+		store.CreateItem("cdf")
+
+		item, err := store.DeleteItem(2)
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 2, Item: "bcd", Version: 1}, item)
+
+		_, err = store.DeleteItem(2)
+		assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 2), err)
+
+		item, err = store.CreateItem("def")
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 4, Item: "def", Version: 1}, item)
+
+		assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 3, Item: "cdf", Version: 1}, {ID: 4, Item: "def", Version: 1}}, store.ReadAll())
+	})
+
+	t.Run("delete all", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd")
+
+		items, err := store.DeleteAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "bcd", Version: 1}}, items)
+		assert.Equal(t, []ItemAndID{}, store.ReadAll())
+		assert.Equal(t, 0, store.Count())
+
+		item, err := store.CreateItem("cdf")
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 3, Item: "cdf", Version: 1}, item)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd", WithParent(1))
+		store.CreateItem("cdf")
+
+		_, err := store.DeleteTree(1)
+		require.Nil(t, err)
+		_, err = store.DeleteItem(3)
+		require.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, trashedIDs(store.Trash()))
+
+		item, err := store.RestoreItem(1)
+		assert.Nil(t, err)
+		assert.Equal(t, ItemAndID{ID: 1, Item: "abc", Version: 2}, item)
+		assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 2}, {ID: 2, Item: "bcd", Version: 2, ParentID: 1}}, store.ReadAll())
+		assert.Equal(t, []int{3}, trashedIDs(store.Trash()))
+
+		purged, err := store.PurgeTrash(now().Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{3}, trashedIDs(purged))
+		assert.Equal(t, []int{}, trashedIDs(store.Trash()))
+
+		_, err = store.RestoreItem(3)
+		assert.Equal(t, NewError(ErrNotFound, "item with id (3) is not in the trash"), err)
+
+		item, err = store.CreateItem("def")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, item.ID)
+	})
+
+	t.Run("purge drops the history", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("secret")
+		store.CreateItem("abc")
+		store.UpdateItem(1, "top secret")
+		asOf := time.Now()
+
+		store.DeleteItem(1)
+		_, err := store.PurgeTrash(now().Add(time.Minute))
+		require.Nil(t, err)
+
+		assert.Equal(t, []ItemAndID{{ID: 2, Item: "abc", Version: 1}}, store.Find(Query{AsOf: &asOf}))
+
+		history, err := store.History(1)
+		require.Nil(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, RevisionPurged, history[0].Op)
+		assert.Nil(t, history[0].Old)
+		assert.Nil(t, history[0].New)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd")
+		store.CreateItem("cdf")
+
+		_, err := store.MoveItem(3, MoveBefore(1))
+		assert.Nil(t, err)
+		_, err = store.MoveItem(1, MoveAfter(2))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{3, 2, 1}, itemIDs(store.ReadAll()))
+
+		// restored items go to the end
+		store.DeleteItem(3)
+		store.RestoreItem(3)
+		assert.Equal(t, []int{2, 1, 3}, itemIDs(store.ReadAll()))
+	})
+
+	t.Run("undo", func(t *testing.T) {
+		store := newStore(t)
+		store.CreateItem("abc")
+		store.CreateItem("bcd")
+		store.CreateItem("cdf")
+
+		_, err := store.DeleteItem(2)
+		require.Nil(t, err)
+		_, err = store.DeleteAll()
+		require.Nil(t, err)
+
+		_, err = store.Undo()
+		assert.Nil(t, err)
+		_, err = store.Undo()
+		assert.Nil(t, err)
+		assert.Equal(t, []ItemAndID{
+			{ID: 1, Item: "abc", Version: 2},
+			{ID: 2, Item: "bcd", Version: 2},
+			{ID: 3, Item: "cdf", Version: 2},
+		}, store.ReadAll())
+		assert.Equal(t, []int{}, trashedIDs(store.Trash()))
+
+		_, err = store.Undo()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, store.Count())
+
+		_, err = store.Redo()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, store.Count())
+
+		item, err := store.CreateItem("def")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, item.ID)
+	})
+}
+
+func trashedIDs(trash []TrashedItem) []int {
+	ids := []int{}
+	for _, item := range trash {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag lower-cases the tag and collapses its whitespace so that
+// "Oncall" and "oncall " are the same tag.
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(tag), " "))
+	if normalized == "" {
+		return "", NewFieldError(ErrValidation, "tags", "tag (%q) is empty", tag)
+	}
+
+	return normalized, nil
+}
+
+// AddTags adds the tags the item doesn't have yet.
+func (il *ItemList) AddTags(id int, tags ...string) (ItemAndID, error) {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return il.modify(id, fmt.Sprintf("tag item %v", id), func(item *ItemAndID) error {
+		item.Tags = mergeTags(item.Tags, normalized)
+		return nil
+	})
+}
+
+// WithTags replaces the item's tags, which have to be normalized already.
+func WithTags(tags ...string) ItemOption {
+	return func(item *ItemAndID) {
+		item.Tags = mergeTags(nil, tags)
+	}
+}
+
+func (il *ItemList) RemoveTag(id int, tag string) (ItemAndID, error) {
+	normalized, err := NormalizeTag(tag)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return il.modify(id, fmt.Sprintf("remove tag %v from item %v", normalized, id), func(item *ItemAndID) error {
+		tags := []string{}
+		for _, t := range item.Tags {
+			if t != normalized {
+				tags = append(tags, t)
+			}
+		}
+
+		if len(tags) == len(item.Tags) {
+			return NewError(ErrNotFound, "item with id (%v) does not have tag (%v)", id, normalized)
+		}
+		if len(tags) == 0 {
+			tags = nil
+		}
+
+		item.Tags = tags
+		return nil
+	})
+}
+
+// Tags returns every tag in use with the number of items carrying it, sorted
+// by tag.
+func (il *ItemList) Tags() []TagCount {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	counts := map[string]int{}
+	for _, item := range il.items {
+		for _, tag := range item.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		n, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized = append(normalized, n)
+	}
+
+	return normalized, nil
+}
+
+// mergeTags returns a new sorted set of tags; the item's slice is never
+// modified in place since copies of the item share it.
+func mergeTags(existing []string, added []string) []string {
+	set := map[string]bool{}
+	for _, tag := range existing {
+		set[tag] = true
+	}
+	for _, tag := range added {
+		set[tag] = true
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	testTable := []struct {
+		name          string
+		tag           string
+		expectedTag   string
+		expectedError error
+	}{
+		{
+			name:        "already normalized",
+			tag:         "oncall",
+			expectedTag: "oncall",
+		},
+		{
+			name:        "case and surrounding whitespace",
+			tag:         " OnCall\t",
+			expectedTag: "oncall",
+		},
+		{
+			name:        "inner whitespace",
+			tag:         "release \t notes",
+			expectedTag: "release notes",
+		},
+		{
+			name:          "only whitespace",
+			tag:           " ",
+			expectedError: NewFieldError(ErrValidation, "tags", "tag (\" \") is empty"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			tag, err := NormalizeTag(testCase.tag)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedTag, tag)
+		})
+	}
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	itemList := itemListOf("abc", "bcd")
+
+	item, err := itemList.AddTags(1, "oncall", "Backend", "oncall ")
+	assert.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 1, Item: "abc", Version: 2, Tags: []string{"backend", "oncall"}}, item)
+
+	// copies handed out earlier are not affected by later changes
+	read, _ := itemList.ReadItem(1)
+	_, err = itemList.RemoveTag(1, "backend")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backend", "oncall"}, read.Tags)
+
+	_, err = itemList.RemoveTag(1, "backend")
+	assert.Equal(t, NewError(ErrNotFound, "item with id (1) does not have tag (backend)"), err)
+
+	_, err = itemList.AddTags(3, "backend")
+	assert.Equal(t, NewError(ErrNotFound, "item with id (%v) does not exist", 3), err)
+
+	itemList.AddTags(2, "oncall")
+	assert.Equal(t, []TagCount{{Tag: "oncall", Count: 2}}, itemList.Tags())
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// TrashedItem is a deleted item, kept in the trash until it is restored or
+// purged. Its ID stays reserved in the meantime.
+type TrashedItem struct {
+	ItemAndID
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Trash returns the deleted items in the order they were deleted in.
+func (il *ItemList) Trash() []TrashedItem {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	return copyTrash(il.trash)
+}
+
+// RestoreItem moves the item from the trash back to the end of the list,
+// along with the subtasks that were deleted with it. An item keeps its ID,
+// but not its blockers. A subtask can only be restored once its parent is
+// back; if the parent has been purged, it becomes a top-level item.
+func (il *ItemList) RestoreItem(id int) (ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	if id < 1 {
+		return ItemAndID{}, NewError(ErrInvalidID, "id is less than 1")
+	}
+
+	index := indexOfTrashed(il.trash, id)
+	if index < 0 {
+		return ItemAndID{}, NewError(ErrNotFound, "item with id (%v) is not in the trash", id)
+	}
+
+	item := il.trash[index].ItemAndID
+	if item.ParentID != 0 && indexOf(il.items, item.ParentID) < 0 {
+		if indexOfTrashed(il.trash, item.ParentID) >= 0 {
+			return ItemAndID{}, NewError(ErrConflict, "item with id (%v) is a subtask of item with id (%v), which is in the trash", id, item.ParentID)
+		}
+
+		item.ParentID = 0
+	}
+
+	trashed := make([]ItemAndID, len(il.trash))
+	for i, t := range il.trash {
+		trashed[i] = t.ItemAndID
+	}
+	restored := flattenTree(buildTree(item, childrenByParent(trashed)))
+
+	var changes []change
+	for i := range restored {
+		changes = append(changes, il.put(&restored[i]))
+	}
+
+	err := il.commit(fmt.Sprintf("restore item %v", id), changes...)
+	if err != nil {
+		return ItemAndID{}, err
+	}
+
+	return restored[0], nil
+}
+
+// PurgeTrash deletes the items that were moved to the trash before the given
+// time for good and returns them.
+func (il *ItemList) PurgeTrash(before time.Time) ([]TrashedItem, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	var purged []TrashedItem
+	var changes []change
+	for _, item := range il.trash {
+		if item.DeletedAt.Before(before) {
+			purged = append(purged, item)
+			changes = append(changes, purgeChange(item.ID))
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	err := il.commit("", changes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
+
+// PurgeTrash purges the items deleted before the given time from every list.
+func (l *Lists) PurgeTrash(before time.Time) error {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	for name, store := range l.stores {
+		purged, err := store.PurgeTrash(before)
+		if err != nil {
+			return fmt.Errorf("error purging the trash of list (%v): %v", name, err)
+		}
+
+		if len(purged) > 0 {
+			logrus.Infof("purged %v items from the trash of list (%v)", len(purged), name)
+		}
+	}
+
+	return nil
+}
+
+// StartJanitor purges items that have been in the trash for longer than the
+// retention period from every list, checking at least once an hour, until
+// the returned function is called.
+func StartJanitor(lists *Lists, retention time.Duration) (stop func()) {
+	interval := time.Hour
+	if retention < interval {
+		interval = retention
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			err := lists.PurgeTrash(now().Add(-retention))
+			if err != nil {
+				logrus.Warnf("error emptying the trash: %v", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// trashChanges returns the changes moving the deleted items to the trash.
+// Their blockers are dropped since unlinkChanges removes the other side.
+func (il *ItemList) trashChanges(deleted []ItemAndID) []change {
+	deletedAt := now().UTC()
+
+	changes := []change{}
+	for _, item := range deleted {
+		item.BlockedBy = nil
+		item.Blocks = nil
+		changes = append(changes, trashChange(item, deletedAt))
+	}
+
+	return changes
+}
+
+func indexOfTrashed(trash []TrashedItem, id int) int {
+	for i, item := range trash {
+		if item.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func copyTrash(trash []TrashedItem) []TrashedItem {
+	trashCpy := make([]TrashedItem, len(trash))
+	copy(trashCpy, trash)
+
+	return trashCpy
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRestoreItem(t *testing.T) {
+	testTable := []struct {
+		name             string
+		id               int
+		expectedResponse ItemAndID
+		expectedIDs      []int
+		expectedError    error
+	}{
+		{
+			name:             "item with subtasks",
+			id:               1,
+			expectedResponse: ItemAndID{ID: 1, Item: "release", Version: 2},
+			expectedIDs:      []int{4, 1, 2, 3},
+		},
+		{
+			name:          "subtask of a trashed item",
+			id:            3,
+			expectedError: NewError(ErrConflict, "item with id (3) is a subtask of item with id (2), which is in the trash"),
+			expectedIDs:   []int{4},
+		},
+		{
+			name:          "item that isn't deleted",
+			id:            4,
+			expectedError: NewError(ErrNotFound, "item with id (4) is not in the trash"),
+			expectedIDs:   []int{4},
+		},
+		{
+			name:          "id is 0",
+			id:            0,
+			expectedError: NewError(ErrInvalidID, "id is less than 1"),
+			expectedIDs:   []int{4},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			itemList := NewItemList()
+			itemList.CreateItem("release")
+			itemList.CreateItem("tag", WithParent(1))
+			itemList.CreateItem("publish", WithParent(2))
+			itemList.CreateItem("unrelated")
+			_, err := itemList.DeleteTree(1)
+			require.Nil(t, err)
+
+			item, err := itemList.RestoreItem(testCase.id)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedResponse, item)
+
+			ids := []int{}
+			for _, item := range itemList.ReadAll() {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+		})
+	}
+}
+
+func TestRestoreItem_PurgedParent(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("release")
+	itemList.CreateItem("tag", WithParent(1))
+	_, err := itemList.DeleteTree(1)
+	require.Nil(t, err)
+
+	// drop the parent from the trash as if it had been purged on its own
+	itemList.trash = itemList.trash[1:]
+
+	item, err := itemList.RestoreItem(2)
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 2, Item: "tag", Version: 2}, item)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	deletedAt := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+	now = func() time.Time { return deletedAt }
+	defer func() { now = time.Now }()
+
+	itemList := itemListOf("abc", "bcd", "cdf")
+	itemList.DeleteItem(1)
+	now = func() time.Time { return deletedAt.Add(time.Hour) }
+	itemList.DeleteItem(2)
+
+	purged, err := itemList.PurgeTrash(deletedAt.Add(time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, []TrashedItem{{ItemAndID: ItemAndID{ID: 1, Item: "abc", Version: 1}, DeletedAt: deletedAt}}, purged)
+	assert.Equal(t, []TrashedItem{{ItemAndID: ItemAndID{ID: 2, Item: "bcd", Version: 1}, DeletedAt: deletedAt.Add(time.Hour)}}, itemList.Trash())
+
+	purged, err = itemList.PurgeTrash(deletedAt.Add(time.Minute))
+	require.Nil(t, err)
+	assert.Nil(t, purged)
+}
+
+func TestStartJanitor(t *testing.T) {
+	lists, err := NewLists(NewItemList(), MemoryLists{})
+	require.Nil(t, err)
+	lists.Create("sprint")
+	sprint, _ := lists.Get("sprint")
+
+	lists.Default().CreateItem("abc")
+	lists.Default().DeleteItem(1)
+	sprint.CreateItem("bcd")
+	sprint.DeleteItem(1)
+
+	stop := StartJanitor(lists, 10*time.Millisecond)
+	defer stop()
+
+	assert.Eventually(t, func() bool {
+		return len(lists.Default().Trash()) == 0 && len(sprint.Trash()) == 0
+	}, time.Second, 5*time.Millisecond)
+}
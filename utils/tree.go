@@ -0,0 +1,159 @@
+package utils
+
+import "fmt"
+
+// Progress counts the done items among an item's descendants.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ItemTree is an item along with its subtasks. Progress is only set for
+// items that have subtasks.
+type ItemTree struct {
+	ItemAndID
+	Progress *Progress  `json:"progress,omitempty"`
+	Children []ItemTree `json:"children,omitempty"`
+}
+
+// WithParent makes the item a subtask of the item with the given id; 0 makes
+// it a top-level item.
+func WithParent(parentID int) ItemOption {
+	return func(item *ItemAndID) {
+		item.ParentID = parentID
+	}
+}
+
+// ReadTree returns the item with all of its descendants.
+func (il *ItemList) ReadTree(id int) (ItemTree, error) {
+	il.m.RLock()
+	defer il.m.RUnlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return ItemTree{}, err
+	}
+
+	return buildTree(il.items[index], childrenByParent(il.items)), nil
+}
+
+// DeleteTree moves the item along with all of its descendants to the trash
+// and returns them, parents before their children. IfVersion applies to the
+// item itself, not its descendants.
+func (il *ItemList) DeleteTree(id int, opts ...ItemOption) ([]ItemAndID, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	index, err := il.indexOf(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkVersion(il.items[index], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := flattenTree(buildTree(il.items[index], childrenByParent(il.items)))
+
+	changes := append(il.unlinkChanges(deleted), il.trashChanges(deleted)...)
+
+	err = il.commitAs(fmt.Sprintf("delete item %v and its subtasks", id), actorOf(opts), changes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// BuildForest arranges the items into trees, keeping their order. Items whose
+// parent is not among the items become roots.
+func BuildForest(items []ItemAndID) []ItemTree {
+	present := map[int]bool{}
+	for _, item := range items {
+		present[item.ID] = true
+	}
+
+	children := childrenByParent(items)
+
+	forest := []ItemTree{}
+	for _, item := range items {
+		if item.ParentID == 0 || !present[item.ParentID] {
+			forest = append(forest, buildTree(item, children))
+		}
+	}
+
+	return forest
+}
+
+// validateParent checks that the item's parent exists and that the item is
+// not its own ancestor. Must be called with il.m locked.
+func (il *ItemList) validateParent(item ItemAndID) error {
+	for parentID := item.ParentID; parentID != 0; {
+		if parentID == item.ID {
+			return NewFieldError(ErrValidation, "parent_id", "item with id (%v) can't be a subtask of itself or its subtasks", item.ID)
+		}
+
+		index := indexOf(il.items, parentID)
+		if index < 0 {
+			return NewFieldError(ErrValidation, "parent_id", "parent item with id (%v) does not exist", parentID)
+		}
+
+		parentID = il.items[index].ParentID
+	}
+
+	return nil
+}
+
+func (il *ItemList) hasChildren(id int) bool {
+	for _, item := range il.items {
+		if item.ParentID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func childrenByParent(items []ItemAndID) map[int][]ItemAndID {
+	children := map[int][]ItemAndID{}
+	for _, item := range items {
+		if item.ParentID != 0 {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		}
+	}
+
+	return children
+}
+
+func buildTree(item ItemAndID, children map[int][]ItemAndID) ItemTree {
+	tree := ItemTree{ItemAndID: item}
+
+	for _, child := range children[item.ID] {
+		subtree := buildTree(child, children)
+		tree.Children = append(tree.Children, subtree)
+
+		if tree.Progress == nil {
+			tree.Progress = &Progress{}
+		}
+		tree.Progress.Total++
+		if child.Done {
+			tree.Progress.Done++
+		}
+		if subtree.Progress != nil {
+			tree.Progress.Total += subtree.Progress.Total
+			tree.Progress.Done += subtree.Progress.Done
+		}
+	}
+
+	return tree
+}
+
+func flattenTree(tree ItemTree) []ItemAndID {
+	items := []ItemAndID{tree.ItemAndID}
+	for _, child := range tree.Children {
+		items = append(items, flattenTree(child)...)
+	}
+
+	return items
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidateParent(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("a")
+	itemList.CreateItem("b", WithParent(1))
+	itemList.CreateItem("c", WithParent(2))
+
+	testTable := []struct {
+		name          string
+		id            int
+		parentID      int
+		expectedError error
+	}{
+		{
+			name:     "top level",
+			id:       3,
+			parentID: 0,
+		},
+		{
+			name:     "sibling",
+			id:       3,
+			parentID: 1,
+		},
+		{
+			name:          "itself",
+			id:            2,
+			parentID:      2,
+			expectedError: NewFieldError(ErrValidation, "parent_id", "item with id (2) can't be a subtask of itself or its subtasks"),
+		},
+		{
+			name:          "own descendant",
+			id:            1,
+			parentID:      3,
+			expectedError: NewFieldError(ErrValidation, "parent_id", "item with id (1) can't be a subtask of itself or its subtasks"),
+		},
+		{
+			name:          "nonexistent parent",
+			id:            1,
+			parentID:      4,
+			expectedError: NewFieldError(ErrValidation, "parent_id", "parent item with id (4) does not exist"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			item, err := itemList.ReadItem(testCase.id)
+			require.Nil(t, err)
+
+			item.ParentID = testCase.parentID
+			assert.Equal(t, testCase.expectedError, itemList.validateParent(item))
+		})
+	}
+}
+
+func TestBuildForest(t *testing.T) {
+	items := []ItemAndID{
+		{ID: 1, Item: "a", Version: 1},
+		{ID: 3, Item: "c", Version: 1, ParentID: 1, Done: true},
+		{ID: 4, Item: "d", Version: 1, ParentID: 2},
+		{ID: 5, Item: "e", Version: 1, ParentID: 3},
+	}
+
+	// 4's parent is filtered out, so it becomes a root
+	assert.Equal(t, []ItemTree{
+		{
+			ItemAndID: ItemAndID{ID: 1, Item: "a", Version: 1},
+			Progress:  &Progress{Done: 1, Total: 2},
+			Children: []ItemTree{
+				{
+					ItemAndID: ItemAndID{ID: 3, Item: "c", Version: 1, ParentID: 1, Done: true},
+					Progress:  &Progress{Done: 0, Total: 1},
+					Children: []ItemTree{
+						{ItemAndID: ItemAndID{ID: 5, Item: "e", Version: 1, ParentID: 3}},
+					},
+				},
+			},
+		},
+		{ItemAndID: ItemAndID{ID: 4, Item: "d", Version: 1, ParentID: 2}},
+	}, BuildForest(items))
+}
+
+func TestDeleteTree(t *testing.T) {
+	itemList := NewItemList()
+	itemList.CreateItem("a")
+	itemList.CreateItem("b", WithParent(1))
+	itemList.CreateItem("c")
+	itemList.CreateItem("d", WithParent(2))
+
+	_, err := itemList.DeleteItem(2)
+	assert.Equal(t, NewError(ErrConflict, "item with id (2) has subtasks"), err)
+
+	deleted, err := itemList.DeleteTree(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 2, Item: "b", Version: 1, ParentID: 1}, {ID: 4, Item: "d", Version: 1, ParentID: 2}}, deleted)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "a", Version: 1}, {ID: 3, Item: "c", Version: 1}}, itemList.ReadAll())
+
+	// a leaf is deleted on its own
+	deleted, err = itemList.DeleteTree(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 3, Item: "c", Version: 1}}, deleted)
+}
@@ -0,0 +1,361 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DefaultUndoDepth is the number of operations that can be undone unless
+// SetUndoDepth says otherwise.
+const DefaultUndoDepth = 100
+
+// Operation describes an operation that was undone or redone.
+type Operation struct {
+	// Description says what the operation did, e.g. "delete item 3".
+	Description string `json:"description"`
+	// Items are the items the operation affected that are in the list now
+	// that it has been undone or redone, in list order, and Removed the ids
+	// of those that aren't.
+	Items   []ItemAndID `json:"items"`
+	Removed []int       `json:"removed,omitempty"`
+}
+
+// operation is a committed operation along with the state of every item it
+// changed before and after it. Operations are kept in the list's state, so
+// durable stores persist them like the items.
+type operation struct {
+	Description string      `json:"description"`
+	Before      []itemState `json:"before"`
+	After       []itemState `json:"after"`
+}
+
+// itemState is where an item is and what it looks like; Item is nil if the
+// item is neither in the list nor in the trash. Index is the item's position
+// in the list and Next the id of the item following it, or 0 if it is the
+// last one.
+type itemState struct {
+	ID      int        `json:"id"`
+	Item    *ItemAndID `json:"item,omitempty"`
+	Trashed bool       `json:"trashed,omitempty"`
+	Index   int        `json:"index"`
+	Next    int        `json:"next,omitempty"`
+}
+
+// SetUndoDepth sets the number of operations that can be undone; 0 disables
+// undo. Operations beyond the new depth are forgotten.
+func (il *ItemList) SetUndoDepth(depth int) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	if depth < 0 {
+		depth = 0
+	}
+
+	il.undoDepth = depth
+	il.undo = lastOperations(il.undo, depth)
+	il.redo = lastOperations(il.redo, depth)
+}
+
+// Undo reverts the most recent operation that hasn't been undone yet,
+// putting deleted items back where they were with their original IDs, and
+// returns what it was. The undone operation can be redone until another
+// operation is made.
+func (il *ItemList) Undo() (Operation, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	if len(il.undo) == 0 {
+		return Operation{}, NewError(ErrConflict, "there is nothing to undo")
+	}
+
+	op := il.undo[len(il.undo)-1]
+	current := il.statesOf(stateIDs(op.After))
+	changes, err := il.transition(op.After, op.Before)
+	if err != nil {
+		return Operation{}, fmt.Errorf("can't undo %v: %w", op.Description, err)
+	}
+
+	states := il.statesAfter(changes, stateIDs(op.Before))
+	op.Before, op.After = states, current
+	// the operation changes stacks first so that the right one is taken off
+	// even if the changes purge an item and forget its operations
+	err = il.commit("", append([]change{{Op: opUndo, Operation: &op, Depth: il.undoDepth}}, changes...)...)
+	if err != nil {
+		return Operation{}, fmt.Errorf("can't undo %v: %w", op.Description, err)
+	}
+
+	return describe(op.Description, states), nil
+}
+
+// Redo makes the most recently undone operation again and returns what it
+// was.
+func (il *ItemList) Redo() (Operation, error) {
+	il.m.Lock()
+	defer il.m.Unlock()
+
+	if len(il.redo) == 0 {
+		return Operation{}, NewError(ErrConflict, "there is nothing to redo")
+	}
+
+	op := il.redo[len(il.redo)-1]
+	current := il.statesOf(stateIDs(op.Before))
+	changes, err := il.transition(op.Before, op.After)
+	if err != nil {
+		return Operation{}, fmt.Errorf("can't redo %v: %w", op.Description, err)
+	}
+
+	states := il.statesAfter(changes, stateIDs(op.After))
+	op.Before, op.After = current, states
+	// the operation changes stacks first so that the right one is taken off
+	// even if the changes purge an item and forget its operations
+	err = il.commit("", append([]change{{Op: opRedo, Operation: &op, Depth: il.undoDepth}}, changes...)...)
+	if err != nil {
+		return Operation{}, fmt.Errorf("can't redo %v: %w", op.Description, err)
+	}
+
+	return describe(op.Description, states), nil
+}
+
+// transition returns the changes that take the items from their states in
+// from, which have to be their current ones apart from the versions, to
+// those in to. Items keep their IDs and their versions keep increasing. Must be
+// called with il.m locked.
+func (il *ItemList) transition(from []itemState, to []itemState) ([]change, error) {
+	for _, state := range from {
+		if !il.stateOf(state.ID).sameAs(state) {
+			return nil, NewError(ErrConflict, "item with id (%v) has changed since", state.ID)
+		}
+	}
+
+	// items that go back into the list are inserted before the item that
+	// followed them, which may be going back too, so insert them from the
+	// end of the list to its start
+	targets := make([]itemState, len(to))
+	copy(targets, to)
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Index > targets[j].Index
+	})
+
+	// an item is moved back in front of the item that followed it only if
+	// that one is in the list afterwards, since it may have been purged
+	listed := map[int]bool{}
+	for _, item := range il.items {
+		listed[item.ID] = true
+	}
+	for _, state := range to {
+		listed[state.ID] = state.Item != nil && !state.Trashed
+	}
+	moves := func(current itemState, target itemState) bool {
+		return current.Next != target.Next && (target.Next == 0 || listed[target.Next])
+	}
+
+	changes := []change{}
+	for _, target := range targets {
+		current := il.stateOf(target.ID)
+		if current.sameAs(target) {
+			if target.Item != nil && !target.Trashed && moves(current, target) {
+				changes = append(changes, moveChange(target.ID, target.Next))
+			}
+			continue
+		}
+
+		switch {
+		case target.Item == nil && current.Trashed:
+			changes = append(changes, purgeChange(target.ID))
+		case target.Item == nil:
+			changes = append(changes, change{Op: opRemove, ID: target.ID})
+		case target.Trashed:
+			changes = append(changes, trashChange(revive(target, current), now().UTC()))
+		case current.Item != nil && !current.Trashed:
+			changes = append(changes, putChange(revive(target, current)))
+			if moves(current, target) {
+				changes = append(changes, moveChange(target.ID, target.Next))
+			}
+		default:
+			c := putChange(revive(target, current))
+			c.Before = target.Next
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+// revive returns the item as it was in the target state, with the version
+// following its current one, or the target's if it doesn't exist anymore.
+func revive(target itemState, current itemState) ItemAndID {
+	item := *target.Item
+	if current.Item != nil {
+		item.Version = current.Item.Version
+	}
+	item.Version++
+
+	return item
+}
+
+// forgetOperations drops the operations that changed the item, since they
+// can't be undone or redone once it has been purged.
+func forgetOperations(ops []operation, id int) []operation {
+	kept := []operation{}
+	for _, op := range ops {
+		if !changesItem(op, id) {
+			kept = append(kept, op)
+		}
+	}
+
+	return kept
+}
+
+func changesItem(op operation, id int) bool {
+	for _, state := range op.Before {
+		if state.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stateOf returns the item's current state. Must be called with il.m locked.
+func (il *ItemList) stateOf(id int) itemState {
+	return stateIn(il.items, il.trash, id)
+}
+
+func (il *ItemList) statesOf(ids []int) []itemState {
+	return statesIn(il.items, il.trash, ids)
+}
+
+// statesAfter returns the states the items will be in once the changes have
+// been applied, without applying them. Must be called with il.m locked.
+func (il *ItemList) statesAfter(changes []change, ids []int) []itemState {
+	s := applyChanges(listSnapshot{Items: copyItems(il.items), Trash: copyTrash(il.trash)}, changes)
+
+	return statesIn(s.Items, s.Trash, ids)
+}
+
+func stateIn(items []ItemAndID, trash []TrashedItem, id int) itemState {
+	if index := indexOf(items, id); index >= 0 {
+		item := items[index]
+		state := itemState{ID: id, Item: &item, Index: index}
+		if index+1 < len(items) {
+			state.Next = items[index+1].ID
+		}
+		return state
+	}
+
+	if index := indexOfTrashed(trash, id); index >= 0 {
+		item := trash[index].ItemAndID
+		return itemState{ID: id, Item: &item, Trashed: true}
+	}
+
+	return itemState{ID: id}
+}
+
+func statesIn(items []ItemAndID, trash []TrashedItem, ids []int) []itemState {
+	states := make([]itemState, len(ids))
+	for i, id := range ids {
+		states[i] = stateIn(items, trash, id)
+	}
+
+	return states
+}
+
+// sameAs reports whether both states have the item look the same in the same
+// place, regardless of its position in the list and its version, which
+// undoing an operation bumps.
+func (s itemState) sameAs(other itemState) bool {
+	if s.ID != other.ID || s.Trashed != other.Trashed || (s.Item == nil) != (other.Item == nil) {
+		return false
+	}
+	if s.Item == nil {
+		return true
+	}
+
+	item, otherItem := *s.Item, *other.Item
+	item.Version, otherItem.Version = 0, 0
+
+	return reflect.DeepEqual(item, otherItem)
+}
+
+// describe summarizes the operation with the items in the given states.
+func describe(description string, states []itemState) Operation {
+	listed := []itemState{}
+	var removed []int
+	for _, state := range states {
+		if state.Item != nil && !state.Trashed {
+			listed = append(listed, state)
+		} else {
+			removed = append(removed, state.ID)
+		}
+	}
+	sort.Slice(listed, func(i, j int) bool {
+		return listed[i].Index < listed[j].Index
+	})
+
+	op := Operation{Description: description, Items: []ItemAndID{}, Removed: removed}
+	for _, state := range listed {
+		op.Items = append(op.Items, *state.Item)
+	}
+
+	return op
+}
+
+// changedIDs returns the ids of the items the changes put, trash, move or
+// remove, in the order they are first changed in.
+func changedIDs(changes []change) []int {
+	seen := map[int]bool{}
+	ids := []int{}
+	for _, c := range changes {
+		var id int
+		switch c.Op {
+		case opPut, opTrash:
+			id = c.Item.ID
+		case opPurge, opRemove, opMove:
+			id = c.ID
+		default:
+			continue
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func stateIDs(states []itemState) []int {
+	ids := make([]int, len(states))
+	for i, state := range states {
+		ids[i] = state.ID
+	}
+
+	return ids
+}
+
+// lastOperations returns the last n operations.
+func lastOperations(ops []operation, n int) []operation {
+	if len(ops) <= n {
+		return ops
+	}
+
+	return append([]operation{}, ops[len(ops)-n:]...)
+}
+
+// dropLastOperation returns the operations without the most recent one.
+func dropLastOperation(ops []operation) []operation {
+	if len(ops) == 0 {
+		return ops
+	}
+
+	return ops[:len(ops)-1]
+}
+
+func copyOperations(ops []operation) []operation {
+	opsCpy := make([]operation, len(ops))
+	copy(opsCpy, ops)
+
+	return opsCpy
+}
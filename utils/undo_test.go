@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUndo(t *testing.T) {
+	testTable := []struct {
+		name              string
+		operation         func(itemList *ItemList) error
+		expectedOperation Operation
+		expectedItems     []ItemAndID
+		expectedTrash     []int
+	}{
+		{
+			name: "create",
+			operation: func(itemList *ItemList) error {
+				_, err := itemList.CreateItem("def")
+				return err
+			},
+			expectedOperation: Operation{Description: "create item 4", Items: []ItemAndID{}, Removed: []int{4}},
+			expectedItems:     []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "bcd", Version: 1}, {ID: 3, Item: "cdf", Version: 1}},
+			expectedTrash:     []int{},
+		},
+		{
+			name: "update",
+			operation: func(itemList *ItemList) error {
+				_, err := itemList.UpdateItem(2, "123", WithNotes("notes"))
+				return err
+			},
+			expectedOperation: Operation{Description: "update item 2", Items: []ItemAndID{{ID: 2, Item: "bcd", Version: 3}}},
+			expectedItems:     []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "bcd", Version: 3}, {ID: 3, Item: "cdf", Version: 1}},
+			expectedTrash:     []int{},
+		},
+		{
+			name: "delete",
+			operation: func(itemList *ItemList) error {
+				_, err := itemList.DeleteItem(2)
+				return err
+			},
+			expectedOperation: Operation{Description: "delete item 2", Items: []ItemAndID{{ID: 2, Item: "bcd", Version: 2}}},
+			expectedItems:     []ItemAndID{{ID: 1, Item: "abc", Version: 1}, {ID: 2, Item: "bcd", Version: 2}, {ID: 3, Item: "cdf", Version: 1}},
+			expectedTrash:     []int{},
+		},
+		{
+			name: "delete all",
+			operation: func(itemList *ItemList) error {
+				_, err := itemList.DeleteAll()
+				return err
+			},
+			expectedOperation: Operation{Description: "delete all items", Items: []ItemAndID{{ID: 1, Item: "abc", Version: 2}, {ID: 2, Item: "bcd", Version: 2}, {ID: 3, Item: "cdf", Version: 2}}},
+			expectedItems:     []ItemAndID{{ID: 1, Item: "abc", Version: 2}, {ID: 2, Item: "bcd", Version: 2}, {ID: 3, Item: "cdf", Version: 2}},
+			expectedTrash:     []int{},
+		},
+		{
+			name: "restore",
+			operation: func(itemList *ItemList) error {
+				_, err := itemList.DeleteItem(1)
+				if err == nil {
+					_, err = itemList.RestoreItem(1)
+				}
+				return err
+			},
+			expectedOperation: Operation{Description: "restore item 1", Removed: []int{1}, Items: []ItemAndID{}},
+			expectedItems:     []ItemAndID{{ID: 2, Item: "bcd", Version: 1}, {ID: 3, Item: "cdf", Version: 1}},
+			expectedTrash:     []int{1},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			itemList := itemListOf("abc", "bcd", "cdf")
+			require.Nil(t, testCase.operation(itemList))
+
+			op, err := itemList.Undo()
+
+			require.Nil(t, err)
+			assert.Equal(t, testCase.expectedOperation, op)
+			assert.Equal(t, testCase.expectedItems, itemList.ReadAll())
+			assert.Equal(t, testCase.expectedTrash, trashedIDs(itemList.Trash()))
+		})
+	}
+}
+
+func TestUndo_DeleteRestoresBlockers(t *testing.T) {
+	itemList := itemListOf("abc", "bcd", "cdf")
+	_, err := itemList.AddBlocker(3, 2)
+	require.Nil(t, err)
+
+	_, err = itemList.DeleteItem(2)
+	require.Nil(t, err)
+	assert.Nil(t, itemList.ReadAll()[1].BlockedBy)
+
+	op, err := itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "delete item 2", op.Description)
+	assert.Equal(t, []ItemAndID{
+		{ID: 1, Item: "abc", Version: 1},
+		{ID: 2, Item: "bcd", Version: 3, Blocks: []int{3}},
+		{ID: 3, Item: "cdf", Version: 4, BlockedBy: []int{2}},
+	}, itemList.ReadAll())
+}
+
+func TestRedo(t *testing.T) {
+	itemList := itemListOf("abc", "bcd")
+	itemList.UpdateItem(1, "123")
+	itemList.DeleteItem(2)
+
+	_, err := itemList.Redo()
+	assert.Equal(t, NewError(ErrConflict, "there is nothing to redo"), err)
+
+	_, err = itemList.Undo()
+	require.Nil(t, err)
+	_, err = itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 3}, {ID: 2, Item: "bcd", Version: 2}}, itemList.ReadAll())
+
+	op, err := itemList.Redo()
+	require.Nil(t, err)
+	assert.Equal(t, Operation{Description: "update item 1", Items: []ItemAndID{{ID: 1, Item: "123", Version: 4}}}, op)
+
+	op, err = itemList.Redo()
+	require.Nil(t, err)
+	assert.Equal(t, Operation{Description: "delete item 2", Items: []ItemAndID{}, Removed: []int{2}}, op)
+	assert.Equal(t, []ItemAndID{{ID: 1, Item: "123", Version: 4}}, itemList.ReadAll())
+	assert.Equal(t, []int{2}, trashedIDs(itemList.Trash()))
+
+	// a new operation can't be followed by a redo
+	_, err = itemList.Undo()
+	require.Nil(t, err)
+	itemList.CreateItem("cdf")
+	_, err = itemList.Redo()
+	assert.Equal(t, NewError(ErrConflict, "there is nothing to redo"), err)
+
+	// ids aren't reused after a create is undone
+	_, err = itemList.Undo()
+	require.Nil(t, err)
+	history, err := itemList.History(3)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(history))
+	assert.Equal(t, RevisionCreated, history[0].Op)
+	assert.Equal(t, RevisionDeleted, history[1].Op)
+	assert.Nil(t, history[1].New)
+
+	item, err := itemList.CreateItem("def")
+	require.Nil(t, err)
+	assert.Equal(t, 4, item.ID)
+}
+
+func TestUndo_Depth(t *testing.T) {
+	itemList := itemListOf("abc", "bcd", "cdf")
+	itemList.SetUndoDepth(2)
+
+	op, err := itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "create item 3", op.Description)
+	op, err = itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "create item 2", op.Description)
+	_, err = itemList.Undo()
+	assert.Equal(t, NewError(ErrConflict, "there is nothing to undo"), err)
+
+	itemList.SetUndoDepth(0)
+	itemList.CreateItem("def")
+	_, err = itemList.Undo()
+	assert.Equal(t, NewError(ErrConflict, "there is nothing to undo"), err)
+}
+
+func TestUndo_Purged(t *testing.T) {
+	itemList := itemListOf("abc", "bcd")
+	itemList.DeleteItem(1)
+	itemList.UpdateItem(2, "123")
+
+	_, err := itemList.PurgeTrash(now().Add(time.Minute))
+	require.Nil(t, err)
+
+	// the delete of the purged item is skipped
+	op, err := itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "update item 2", op.Description)
+	op, err = itemList.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "create item 2", op.Description)
+	_, err = itemList.Undo()
+	assert.Equal(t, NewError(ErrConflict, "there is nothing to undo"), err)
+}
+
+func TestUndo_Reopened(t *testing.T) {
+	testTable := []struct {
+		name string
+		open func(t *testing.T, path string) Store
+	}{
+		{
+			name: "json",
+			open: func(t *testing.T, path string) Store {
+				store, err := NewFileStore(path)
+				require.Nil(t, err)
+				return store
+			},
+		},
+		{
+			name: "wal",
+			open: func(t *testing.T, path string) Store {
+				store, err := NewWALStore(path, 2)
+				require.Nil(t, err)
+				t.Cleanup(func() { store.Close() })
+				return store
+			},
+		},
+		{
+			name: "sqlite",
+			open: func(t *testing.T, path string) Store {
+				store, err := NewSQLStore(path)
+				require.Nil(t, err)
+				t.Cleanup(func() { store.Close() })
+				return store
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "items")
+
+			store := testCase.open(t, path)
+			store.CreateItem("abc")
+			store.CreateItem("bcd")
+			store.CreateItem("cdf")
+			store.UpdateItem(1, "123")
+			store.DeleteItem(2)
+			store.PurgeTrash(now().Add(time.Minute))
+			_, err := store.Undo()
+			require.Nil(t, err)
+			store.Close()
+
+			// the purged item's operations are gone, the undone update can be
+			// redone and undone again
+			store = testCase.open(t, path)
+			op, err := store.Redo()
+			require.Nil(t, err)
+			assert.Equal(t, "update item 1", op.Description)
+			op, err = store.Undo()
+			require.Nil(t, err)
+			assert.Equal(t, "update item 1", op.Description)
+			store.Close()
+
+			store = testCase.open(t, path)
+			op, err = store.Undo()
+			require.Nil(t, err)
+			assert.Equal(t, Operation{Description: "create item 3", Items: []ItemAndID{}, Removed: []int{3}}, op)
+			assert.Equal(t, []ItemAndID{{ID: 1, Item: "abc", Version: 5}}, store.ReadAll())
+
+			op, err = store.Redo()
+			require.Nil(t, err)
+			assert.Equal(t, "create item 3", op.Description)
+			assert.Equal(t, []int{1, 3}, itemIDs(store.ReadAll()))
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+
+	// every record starts with the payload length, the CRC-32C of the
+	// length and the CRC-32C of the payload; the length has a checksum of
+	// its own so that a damaged length is told apart from a torn record
+	walHeaderSize = 12
+	// a length above this is reported as corruption rather than allocated
+	walMaxRecordSize = 64 << 20
+
+	DefaultCompactEvery = 1000
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WALStore is an ItemList persisted as a snapshot plus an append-only
+// write-ahead log. Every mutation appends one record holding its changes and
+// fsyncs the log before the change is applied. After compactEvery records the
+// state is written to a new snapshot and the log is truncated.
+//
+// On startup the snapshot is loaded and the log replayed on top of it. A torn
+// final record, as left behind by a crash in the middle of an append, is
+// discarded; damage anywhere else in the log is reported as an error.
+type WALStore struct {
+	*ItemList
+	dir          string
+	log          *os.File
+	size         int64
+	seq          uint64
+	pending      int
+	compactEvery int
+	closeOnce    sync.Once
+}
+
+var _ Store = (*WALStore)(nil)
+
+type walRecord struct {
+	Seq     uint64   `json:"seq"`
+	Changes []change `json:"changes"`
+}
+
+// NewWALStore opens, or creates, the store in dir. compactEvery is the number
+// of records after which the log is compacted; DefaultCompactEvery is used if
+// it is not positive.
+func NewWALStore(dir string, compactEvery int) (*WALStore, error) {
+	if compactEvery <= 0 {
+		compactEvery = DefaultCompactEvery
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &WALStore{
+		ItemList:     NewItemList(),
+		dir:          dir,
+		compactEvery: compactEvery,
+	}
+
+	err = ws.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	ws.log, err = os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ws.replay()
+	if err != nil {
+		ws.log.Close()
+		return nil, err
+	}
+
+	ws.journal = ws
+
+	return ws, nil
+}
+
+// Compact writes the current state to a new snapshot and truncates the log.
+func (ws *WALStore) Compact() error {
+	ws.m.Lock()
+	defer ws.m.Unlock()
+
+	return ws.compact(ws.snapshot())
+}
+
+func (ws *WALStore) Close() error {
+	var err error
+	ws.closeOnce.Do(func() {
+		ws.m.Lock()
+		defer ws.m.Unlock()
+
+		ws.closed = true
+		err = ws.log.Close()
+	})
+
+	return err
+}
+
+func (ws *WALStore) record(il *ItemList, changes []change) error {
+	payload, err := json.Marshal(walRecord{Seq: ws.seq + 1, Changes: changes})
+	if err != nil {
+		return err
+	}
+
+	err = ws.append(payload)
+	if err != nil {
+		return err
+	}
+
+	ws.seq++
+	ws.pending++
+
+	if ws.pending >= ws.compactEvery {
+		// the record is already durable so the mutation has to go ahead; a
+		// failed compaction is simply retried after the next record
+		err = ws.compact(applyChanges(il.snapshot(), changes))
+		if err != nil {
+			logrus.Warnf("error compacting write-ahead log in %v: %v", ws.dir, err)
+		}
+	}
+
+	return nil
+}
+
+func (ws *WALStore) append(payload []byte) error {
+	b := make([]byte, walHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(b[4:8], crc32.Checksum(b[0:4], crcTable))
+	binary.LittleEndian.PutUint32(b[8:12], crc32.Checksum(payload, crcTable))
+	copy(b[walHeaderSize:], payload)
+
+	_, err := ws.log.WriteAt(b, ws.size)
+	if err == nil {
+		err = ws.log.Sync()
+	}
+	if err != nil {
+		// drop whatever part of the record made it to disk so that it can't
+		// end up in the middle of the log once later records are appended
+		ws.log.Truncate(ws.size)
+		return fmt.Errorf("error appending to write-ahead log: %v", err)
+	}
+
+	ws.size += int64(len(b))
+
+	return nil
+}
+
+// compact must be called with ws.m locked for writing.
+func (ws *WALStore) compact(s listSnapshot) error {
+	s.Seq = ws.seq
+
+	b, err := encodeSnapshot(s)
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(filepath.Join(ws.dir, snapshotFileName), b)
+	if err != nil {
+		return err
+	}
+
+	// if we crash before the truncation the records are skipped on replay
+	// since the snapshot's sequence number already covers them
+	err = ws.log.Truncate(0)
+	if err == nil {
+		err = ws.log.Sync()
+	}
+	if err != nil {
+		return err
+	}
+
+	ws.size = 0
+	ws.pending = 0
+
+	return nil
+}
+
+func (ws *WALStore) loadSnapshot() error {
+	path := filepath.Join(ws.dir, snapshotFileName)
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	s, err := decodeSnapshot(b)
+	if err != nil {
+		return fmt.Errorf("error loading %v: %v", path, err)
+	}
+
+	err = ws.restore(s)
+	if err != nil {
+		return fmt.Errorf("error loading %v: %v", path, err)
+	}
+
+	ws.seq = s.Seq
+
+	return nil
+}
+
+// replay applies the log's records to the list, truncating a torn final
+// record. Only a record whose header is intact but whose payload runs past
+// the end of the log, or a final record whose payload doesn't match its
+// checksum, counts as torn; a damaged header is corruption.
+func (ws *WALStore) replay() error {
+	b, err := io.ReadAll(ws.log)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	for offset < len(b) {
+		rest := b[offset:]
+		if len(rest) < walHeaderSize {
+			break
+		}
+
+		if crc32.Checksum(rest[0:4], crcTable) != binary.LittleEndian.Uint32(rest[4:8]) {
+			return fmt.Errorf("write-ahead log is corrupt: checksum mismatch in the header of the record at offset %v", offset)
+		}
+
+		length := int(binary.LittleEndian.Uint32(rest[0:4]))
+		if length > walMaxRecordSize {
+			return fmt.Errorf("write-ahead log is corrupt: record at offset %v is %v bytes long, more than the maximum of %v", offset, length, walMaxRecordSize)
+		}
+		if walHeaderSize+length > len(rest) {
+			break
+		}
+
+		end := walHeaderSize + length
+		payload := rest[walHeaderSize:end]
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(rest[8:12]) {
+			if end == len(rest) {
+				break
+			}
+			return fmt.Errorf("write-ahead log is corrupt: checksum mismatch in record at offset %v", offset)
+		}
+
+		var r walRecord
+		err = json.Unmarshal(payload, &r)
+		if err != nil {
+			return fmt.Errorf("write-ahead log is corrupt: record at offset %v: %v", offset, err)
+		}
+
+		if r.Seq > ws.seq {
+			if r.Seq != ws.seq+1 {
+				return fmt.Errorf("write-ahead log is corrupt: record at offset %v has sequence number %v, expected %v", offset, r.Seq, ws.seq+1)
+			}
+
+			ws.apply(r.Changes)
+			ws.seq = r.Seq
+			ws.pending++
+		}
+
+		offset += end
+	}
+
+	if offset < len(b) {
+		logrus.Warnf("discarding torn record at the end of the write-ahead log in %v (%v bytes)", ws.dir, len(b)-offset)
+
+		err = ws.log.Truncate(int64(offset))
+		if err == nil {
+			err = ws.log.Sync()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	ws.size = int64(offset)
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// walOperations mutates the store and returns the state of the list and the
+// size of the log after every operation. The clock is fixed so that the size
+// of a record doesn't depend on the time it was written at.
+func walOperations(t *testing.T, store *WALStore) ([][]ItemAndID, []int64) {
+	now = func() time.Time { return time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	operations := []func() error{
+		func() error { _, err := store.CreateItem("abc"); return err },
+		func() error { _, err := store.CreateItem("bcd"); return err },
+		func() error { _, err := store.UpdateItem(1, "123"); return err },
+		func() error { _, err := store.CreateItem("cdf"); return err },
+		func() error { _, err := store.DeleteItem(2); return err },
+		func() error { _, err := store.DeleteAll(); return err },
+		func() error { _, err := store.CreateItem("def"); return err },
+	}
+
+	states := [][]ItemAndID{store.ReadAll()}
+	sizes := []int64{store.size}
+	for _, operation := range operations {
+		require.Nil(t, operation())
+		states = append(states, store.ReadAll())
+		sizes = append(sizes, store.size)
+	}
+
+	return states, sizes
+}
+
+func TestWALStore_Replay(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	states, _ := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	reopened, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	defer reopened.Close()
+
+	assert.Equal(t, states[len(states)-1], reopened.ReadAll())
+
+	item, err := reopened.CreateItem("efg")
+	require.Nil(t, err)
+	assert.Equal(t, ItemAndID{ID: 5, Item: "efg", Version: 1}, item)
+}
+
+func TestWALStore_ReplaysMoves(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	store.CreateItem("abc")
+	store.CreateItem("bcd")
+	store.CreateItem("cdf")
+	_, err = store.MoveItem(3, MoveToPosition(1))
+	require.Nil(t, err)
+	require.Nil(t, store.Close())
+
+	reopened, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	defer reopened.Close()
+	assert.Equal(t, []int{3, 1, 2}, itemIDs(reopened.ReadAll()))
+}
+
+func TestWALStore_TornRecordAtEveryOffset(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	states, sizes := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	log, err := os.ReadFile(filepath.Join(dir, walFileName))
+	require.Nil(t, err)
+	require.Equal(t, sizes[len(sizes)-1], int64(len(log)))
+
+	for offset := 0; offset <= len(log); offset++ {
+		// the number of records that made it to disk in full
+		complete := 0
+		for complete+1 < len(sizes) && sizes[complete+1] <= int64(offset) {
+			complete++
+		}
+
+		crashDir := t.TempDir()
+		require.Nil(t, os.WriteFile(filepath.Join(crashDir, walFileName), log[:offset], 0644))
+
+		recovered, err := NewWALStore(crashDir, 100)
+		require.Nil(t, err, "offset %v", offset)
+		assert.Equal(t, states[complete], recovered.ReadAll(), "offset %v", offset)
+
+		// the torn record is gone, so appending after it works
+		_, err = recovered.CreateItem("xyz")
+		require.Nil(t, err)
+		require.Nil(t, recovered.Close())
+
+		recovered, err = NewWALStore(crashDir, 100)
+		require.Nil(t, err, "offset %v", offset)
+		assert.Equal(t, len(states[complete])+1, recovered.Count(), "offset %v", offset)
+		require.Nil(t, recovered.Close())
+	}
+}
+
+func TestWALStore_CorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	_, sizes := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	path := filepath.Join(dir, walFileName)
+	log, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	// flip a byte in the payload of the second record
+	log[sizes[1]+walHeaderSize+2] ^= 0xff
+	require.Nil(t, os.WriteFile(path, log, 0644))
+
+	_, err = NewWALStore(dir, 100)
+	assert.NotNil(t, err)
+}
+
+func TestWALStore_CorruptLength(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	_, sizes := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	path := filepath.Join(dir, walFileName)
+	log, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	// a length running past the end of the log must not pass for a torn
+	// final record when committed records follow it
+	log[sizes[1]+3] ^= 0x7f
+	require.Nil(t, os.WriteFile(path, log, 0644))
+
+	_, err = NewWALStore(dir, 100)
+	assert.NotNil(t, err)
+
+	// and the records after it are left alone
+	after, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, log, after)
+}
+
+func TestWALStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 3)
+	require.Nil(t, err)
+	states, sizes := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	// 7 records with compaction after every 3rd leaves a single record
+	assert.Equal(t, sizes[1], sizes[len(sizes)-1])
+	_, err = os.Stat(filepath.Join(dir, snapshotFileName))
+	assert.Nil(t, err)
+
+	reopened, err := NewWALStore(dir, 3)
+	require.Nil(t, err)
+	assert.Equal(t, states[len(states)-1], reopened.ReadAll())
+
+	require.Nil(t, reopened.Compact())
+	assert.Equal(t, int64(0), reopened.size)
+	require.Nil(t, reopened.Close())
+
+	reopened, err = NewWALStore(dir, 3)
+	require.Nil(t, err)
+	defer reopened.Close()
+	assert.Equal(t, states[len(states)-1], reopened.ReadAll())
+}
+
+func TestWALStore_CrashBeforeLogTruncation(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	states, _ := walOperations(t, store)
+	require.Nil(t, store.Close())
+
+	path := filepath.Join(dir, walFileName)
+	log, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	store, err = NewWALStore(dir, 100)
+	require.Nil(t, err)
+	require.Nil(t, store.Compact())
+	require.Nil(t, store.Close())
+
+	// put the already compacted records back as if truncation never happened
+	require.Nil(t, os.WriteFile(path, log, 0644))
+
+	reopened, err := NewWALStore(dir, 100)
+	require.Nil(t, err)
+	defer reopened.Close()
+	assert.Equal(t, states[len(states)-1], reopened.ReadAll())
+}